@@ -0,0 +1,451 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/dgraph-io/badger"
+	badgerOptions "github.com/dgraph-io/badger/options"
+	"github.com/objectbox/objectbox-go-performance/internal/cmd"
+	"github.com/objectbox/objectbox-go-performance/internal/kv"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+)
+
+func main() {
+	var options = cmd.GetOptions()
+
+	var executable = &BadgerPerf{
+		path:           options.Path,
+		durability:     options.Durability,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
+	}
+
+	var executor = perf.CreateExecutor(executable)
+	defer executor.Close()
+
+	executor.Run(options)
+}
+
+// perf executable using Badger, storing the objects using the binary encoding & the reads of the kv package.
+// Badger has a single key space so each table (i.e. the objects of a type and each index) has its own key prefix.
+type BadgerPerf struct {
+	kv.Store
+	path           string
+	durability     string
+	backendOptions map[string]string // badger options, see badgerOptions()
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	db             *badger.DB
+	sequences      map[string]*badger.Sequence // ID sequences, by the table name
+	txn            *badger.Txn                 // used for PutAsync
+}
+
+// tables with IDs assigned by a sequence
+var sequenceTables = [][]byte{kv.PlainTables.Data, kv.IndexedTables.Data, kv.CustomerTable, kv.OrderTable}
+
+// sequenceBandwidth is the number of IDs leased at once; IDs not used until the database is closed are returned but
+// those leased by a crashed process are skipped, which keeps IDs ascending
+const sequenceBandwidth = 1000
+
+func (exec *BadgerPerf) Init() error {
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(exec.path, 0777); err != nil {
+		return err
+	}
+
+	if err := exec.Open(); err != nil {
+		return err
+	}
+
+	exec.Store.View = exec.view
+
+	return exec.SetModel(perf.ModelPlain)
+}
+
+// parseLoadingMode parses how badger accesses the table or value log files
+func parseLoadingMode(value string) (badgerOptions.FileLoadingMode, error) {
+	switch value {
+	case "file-io":
+		return badgerOptions.FileIO, nil
+	case "load-to-ram":
+		return badgerOptions.LoadToRAM, nil
+	case "memory-map":
+		return badgerOptions.MemoryMap, nil
+	default:
+		return 0, fmt.Errorf("expected file-io, load-to-ram or memory-map")
+	}
+}
+
+// badgerOptions maps the durability level and the backend options to badger options
+func (exec *BadgerPerf) badgerOptions() (badger.Options, error) {
+	var options = badger.DefaultOptions(exec.path)
+	options.Logger = warningLogger{}
+
+	// a value log write torn by a crash is truncated instead of refusing to open the database; only the transaction
+	// being written is lost
+	options.Truncate = true
+
+	switch exec.durability {
+	case perf.DurabilityFull:
+	case perf.DurabilityNormal, perf.DurabilityNone:
+		// badger writes the value log on each commit anyway, it can only skip syncing it, see EffectiveDurability()
+		options.SyncWrites = false
+	default:
+		return options, fmt.Errorf("unknown durability level %s", exec.durability)
+	}
+
+	for key, value := range exec.backendOptions {
+		var err error
+		var number int64
+		switch key {
+		case "max-table-size":
+			options.MaxTableSize, err = strconv.ParseInt(value, 10, 64)
+		case "value-log-file-size":
+			options.ValueLogFileSize, err = strconv.ParseInt(value, 10, 64)
+		case "value-threshold":
+			number, err = strconv.ParseInt(value, 10, 32)
+			options.ValueThreshold = int(number)
+		case "num-memtables":
+			options.NumMemtables, err = strconv.Atoi(value)
+		case "num-compactors":
+			options.NumCompactors, err = strconv.Atoi(value)
+		case "table-loading-mode":
+			options.TableLoadingMode, err = parseLoadingMode(value)
+		case "value-log-loading-mode":
+			options.ValueLogLoadingMode, err = parseLoadingMode(value)
+		default:
+			err = fmt.Errorf("unknown option, expected one of max-table-size, value-log-file-size, value-threshold, " +
+				"num-memtables, num-compactors, table-loading-mode, value-log-loading-mode")
+		}
+		if err != nil {
+			return options, fmt.Errorf("invalid backend option %s=%s: %s", key, value, err)
+		}
+	}
+
+	return options, nil
+}
+
+// warningLogger passes badger's warnings and errors to the log, but not the informational messages about its
+// background work which would clutter the progress log of the tests
+type warningLogger struct{}
+
+func (warningLogger) Errorf(format string, args ...interface{}) {
+	log.Printf("badger error: "+format, args...)
+}
+
+func (warningLogger) Warningf(format string, args ...interface{}) {
+	log.Printf("badger warning: "+format, args...)
+}
+
+func (warningLogger) Infof(string, ...interface{}) {}
+
+func (warningLogger) Debugf(string, ...interface{}) {}
+
+// EffectiveDurability implements perf.DurabilityLimited: badger can't write less than on the normal level, so none runs
+// the same as normal.
+func (exec *BadgerPerf) EffectiveDurability() string {
+	if exec.durability == perf.DurabilityNone {
+		return perf.DurabilityNormal
+	}
+	return exec.durability
+}
+
+// Open opens the database in the existing directory
+func (exec *BadgerPerf) Open() error {
+	options, err := exec.badgerOptions()
+	if err != nil {
+		return err
+	}
+
+	if db, err := badger.Open(options); err != nil {
+		return err
+	} else {
+		exec.db = db
+	}
+
+	exec.sequences = make(map[string]*badger.Sequence)
+	for _, table := range sequenceTables {
+		var key = kv.PrefixedKey([]byte("Sequence"), table)
+		if sequence, err := exec.db.GetSequence(key, sequenceBandwidth); err != nil {
+			return err
+		} else {
+			exec.sequences[string(table)] = sequence
+		}
+	}
+	return nil
+}
+
+func (exec *BadgerPerf) Release() error {
+	for _, sequence := range exec.sequences {
+		if err := sequence.Release(); err != nil {
+			return err
+		}
+	}
+	return exec.db.Close()
+}
+
+func (exec *BadgerPerf) Close() error {
+	if err := exec.Release(); err != nil {
+		return err
+	}
+
+	if exec.keepData {
+		return nil
+	}
+
+	return os.RemoveAll(exec.path)
+}
+
+// Size returns the total size of the LSM tree tables and the value log files
+func (exec *BadgerPerf) Size() (uint64, error) {
+	files, err := ioutil.ReadDir(exec.path)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, file := range files {
+		size += uint64(file.Size())
+	}
+	return size, nil
+}
+
+// transactions
+
+// prefixEnd returns the first key after all the keys with the given prefix, which ends with '/'
+func prefixEnd(prefix []byte) []byte {
+	var end = append([]byte(nil), prefix...)
+	end[len(end)-1]++
+	return end
+}
+
+// iterate calls fn for each key of the table, starting at the given key, until it returns false.
+// The key is passed without the table prefix; the item is only valid until fn returns.
+func iterate(txn *badger.Txn, table, start []byte, reverse, values bool,
+	fn func(key []byte, item *badger.Item) (bool, error)) error {
+	var prefix = kv.KeyPrefix(table)
+	var options = badger.DefaultIteratorOptions
+	options.Prefix = prefix
+	options.PrefetchValues = values
+	options.Reverse = reverse
+
+	var it = txn.NewIterator(options)
+	defer it.Close()
+
+	if reverse {
+		it.Seek(prefixEnd(prefix))
+	} else {
+		it.Seek(append(prefix, start...))
+	}
+
+	for ; it.ValidForPrefix(prefix); it.Next() {
+		var item = it.Item()
+		if next, err := fn(item.Key()[len(prefix):], item); err != nil {
+			return err
+		} else if !next {
+			break
+		}
+	}
+	return nil
+}
+
+// reader reads in a badger transaction, each table is a key prefix
+type reader struct {
+	txn *badger.Txn
+}
+
+func (r reader) Get(table, key []byte) ([]byte, error) {
+	item, err := r.txn.Get(kv.PrefixedKey(table, key))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the value is only valid in item.Value(), it must be copied to be returned
+	return item.ValueCopy(nil)
+}
+
+func (r reader) Iterate(table, start []byte, reverse bool, fn func(key, value []byte) (bool, error)) error {
+	return iterate(r.txn, table, start, reverse, true, func(key []byte, item *badger.Item) (bool, error) {
+		var next bool
+		var err = item.Value(func(value []byte) error {
+			var err error
+			next, err = fn(key, value)
+			return err
+		})
+		return next, err
+	})
+}
+
+func (r reader) IterateKeys(table, start []byte, reverse bool, fn func(key []byte) (bool, error)) error {
+	return iterate(r.txn, table, start, reverse, false, func(key []byte, _ *badger.Item) (bool, error) {
+		return fn(key)
+	})
+}
+
+// writer writes in a read-write badger transaction, IDs are assigned by the sequence of the table
+type writer struct {
+	reader
+	sequences map[string]*badger.Sequence
+}
+
+func (w writer) Set(table, key, value []byte) error {
+	return w.txn.Set(kv.PrefixedKey(table, key), value)
+}
+
+func (w writer) Delete(table, key []byte) error {
+	return w.txn.Delete(kv.PrefixedKey(table, key))
+}
+
+// NextId returns a new ID from the sequence; badger sequences start at zero, which isn't a valid ID
+func (w writer) NextId(table []byte) (uint64, error) {
+	id, err := w.sequences[string(table)].Next()
+	return id + 1, err
+}
+
+// view runs the function in a read-only transaction
+func (exec *BadgerPerf) view(fn func(reader kv.Reader) error) error {
+	return exec.db.View(func(txn *badger.Txn) error {
+		return fn(reader{txn})
+	})
+}
+
+// update runs the function in a read-write transaction, retrying it as long as it conflicts with a concurrent one
+func (exec *BadgerPerf) update(fn func(writer kv.Writer) error) error {
+	for {
+		if err := exec.db.Update(func(txn *badger.Txn) error {
+			return fn(writer{reader{txn}, exec.sequences})
+		}); err != badger.ErrConflict {
+			return err
+		}
+	}
+}
+
+// updateMany calls the function for each of the count items in a single read-write transaction, so that it's atomic
+// like the other databases' bulk writes. Badger limits the size of a transaction to 15 % of max-table-size, a bulk
+// write exceeding it fails, see txnError().
+func (exec *BadgerPerf) updateMany(count int, fn func(writer kv.Writer, i int) error) error {
+	var txn = exec.db.NewTransaction(true)
+	defer txn.Discard()
+
+	var writer = writer{reader{txn}, exec.sequences}
+	for i := 0; i < count; i++ {
+		if err := fn(writer, i); err != nil {
+			return txnError(err)
+		}
+	}
+
+	return txn.Commit()
+}
+
+// txnError explains how to avoid badger.ErrTxnTooBig
+func txnError(err error) error {
+	if err == badger.ErrTxnTooBig {
+		return fmt.Errorf("%s: write fewer objects per transaction (-batch-size) or increase the limit "+
+			"(-backend-opt max-table-size)", err)
+	}
+	return err
+}
+
+// writing
+
+// dropTables removes all keys of the tables
+func (exec *BadgerPerf) dropTables(tables [][]byte) error {
+	var prefixes = make([][]byte, len(tables))
+	for i, table := range tables {
+		prefixes[i] = kv.KeyPrefix(table)
+	}
+	return exec.db.DropPrefix(prefixes...)
+}
+
+func (exec *BadgerPerf) RemoveAll() error {
+	return exec.dropTables(exec.Tables.All())
+}
+
+func (exec *BadgerPerf) RemoveBulk(items []*models.Entity) error {
+	return exec.updateMany(len(items), func(writer kv.Writer, i int) error {
+		return kv.Remove(writer, exec.Tables, items[i].Id)
+	})
+}
+
+func (exec *BadgerPerf) PutAsync(item *models.Entity) error {
+	// PutAsync is simulated by reusing a transaction and committing it afterwards
+	if exec.txn == nil {
+		exec.txn = exec.db.NewTransaction(true)
+	}
+
+	// the transaction isn't split when it gets too big, same as updateMany()
+	if err := kv.Put(writer{reader{exec.txn}, exec.sequences}, exec.Tables, item); err != nil {
+		exec.txn.Discard()
+		exec.txn = nil
+		return txnError(err)
+	}
+	return nil
+}
+
+func (exec *BadgerPerf) AwaitAsyncCompletion() error {
+	if exec.txn != nil {
+		var err = exec.txn.Commit()
+		exec.txn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (exec *BadgerPerf) PutBulk(items []*models.Entity) error {
+	return exec.updateMany(len(items), func(writer kv.Writer, i int) error {
+		return kv.Put(writer, exec.Tables, items[i])
+	})
+}
+
+func (exec *BadgerPerf) Put(item *models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		return kv.Put(writer, exec.Tables, item)
+	})
+}
+
+func (exec *BadgerPerf) Remove(item *models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		return kv.Remove(writer, exec.Tables, item.Id)
+	})
+}
+
+// relations
+
+func (exec *BadgerPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.updateMany(len(customers), func(writer kv.Writer, i int) error {
+		return kv.PutCustomers(writer, customers[i:i+1])
+	})
+}
+
+func (exec *BadgerPerf) RemoveAllCustomers() error {
+	return exec.dropTables(kv.RelationTables)
+}
@@ -17,20 +17,28 @@
 package main
 
 import (
+	"fmt"
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
+	"github.com/objectbox/objectbox-go-performance/internal/boltopt"
 	"github.com/objectbox/objectbox-go-performance/internal/cmd"
 	"github.com/objectbox/objectbox-go-performance/internal/models"
 	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 func main() {
 	var options = cmd.GetOptions()
 
 	var executable = &StormPerf{
-		path: options.Path,
+		path:           options.Path,
+		durability:     options.Durability,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
 	}
 
 	var executor = perf.CreateExecutor(executable)
@@ -41,24 +49,29 @@ func main() {
 
 // perf executable
 type StormPerf struct {
-	path string
-	db   *storm.DB
-	tx   storm.Node // used for PutAsync
+	path           string
+	durability     string
+	backendOptions map[string]string // bolt options: page-size, initial-mmap-size, mmap-flags, freelist-type
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	db             *storm.DB
+	tx             storm.Node // used for PutAsync
+	indexed        bool       // whether to store models.IndexedEntity instead of models.Entity
 }
 
 func (exec *StormPerf) Init() error {
-	if err := os.RemoveAll(exec.path); err != nil {
-		return err
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
 	}
 
-	if err := os.Mkdir(exec.path, 0777); err != nil {
+	if err := os.MkdirAll(exec.path, 0777); err != nil {
 		return err
 	}
 
-	if db, err := storm.Open(filepath.Join(exec.path, "test.db"), storm.BoltOptions(0600, nil)); err != nil {
+	if err := exec.Open(); err != nil {
 		return err
-	} else {
-		exec.db = db
 	}
 
 	// initialize the DB schema by saving an object and removing it
@@ -70,21 +83,67 @@ func (exec *StormPerf) Init() error {
 		return err
 	}
 
+	var indexedProto = &models.IndexedEntity{}
+	if err := exec.db.Save(indexedProto); err != nil {
+		return err
+	} else if err = exec.db.DeleteStruct(indexedProto); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (exec *StormPerf) Close() error {
-	if err := exec.db.Close(); err != nil {
+// Open opens the database in the existing directory
+func (exec *StormPerf) Open() error {
+	boltOptions, err := boltopt.Options(exec.durability, exec.backendOptions)
+	if err != nil {
 		return err
 	}
 
-	if err := os.RemoveAll(exec.path); err != nil {
+	if db, err := storm.Open(filepath.Join(exec.path, "test.db"), storm.BoltOptions(0600, boltOptions)); err != nil {
 		return err
+	} else {
+		exec.db = db
 	}
+	return nil
+}
 
+func (exec *StormPerf) Release() error {
+	return exec.db.Close()
+}
+
+func (exec *StormPerf) SetModel(model string) error {
+	switch model {
+	case perf.ModelPlain:
+		exec.indexed = false
+	case perf.ModelIndexed:
+		exec.indexed = true
+	default:
+		return fmt.Errorf("unknown model %s", model)
+	}
 	return nil
 }
 
+// object returns the given item as the type of the current model, for storm to choose the right bucket and indexes
+func (exec *StormPerf) object(item *models.Entity) interface{} {
+	if exec.indexed {
+		return (*models.IndexedEntity)(item)
+	}
+	return item
+}
+
+func (exec *StormPerf) Close() error {
+	if err := exec.db.Close(); err != nil {
+		return err
+	}
+
+	if exec.keepData {
+		return nil
+	}
+
+	return os.RemoveAll(exec.path)
+}
+
 func (exec *StormPerf) Size() (uint64, error) {
 	if stat, err := os.Stat(filepath.Join(exec.path, "test.db")); err != nil {
 		return 0, err
@@ -94,7 +153,7 @@ func (exec *StormPerf) Size() (uint64, error) {
 }
 
 func (exec *StormPerf) RemoveAll() error {
-	return exec.db.Select().Delete(&models.Entity{})
+	return exec.db.Select().Delete(exec.object(&models.Entity{}))
 }
 
 func (exec *StormPerf) RemoveBulk(items []*models.Entity) error {
@@ -107,7 +166,7 @@ func (exec *StormPerf) RemoveBulk(items []*models.Entity) error {
 
 	return exec.runInTx(func(tx storm.Node) error {
 		for _, object := range items {
-			if err := tx.DeleteStruct(object); err != nil {
+			if err := tx.DeleteStruct(exec.object(object)); err != nil {
 				return err
 			}
 		}
@@ -125,7 +184,7 @@ func (exec *StormPerf) PutAsync(item *models.Entity) error {
 		}
 	}
 
-	if err := exec.tx.Save(item); err != nil {
+	if err := exec.tx.Save(exec.object(item)); err != nil {
 		if err2 := exec.tx.Rollback(); err2 != nil {
 			panic(err2)
 		}
@@ -166,7 +225,7 @@ func (exec *StormPerf) PutBulk(items []*models.Entity) (err error) {
 	// is implemented, we're using manual transactions
 	return exec.runInTx(func(tx storm.Node) error {
 		for _, item := range items {
-			if err := tx.Save(item); err != nil {
+			if err := tx.Save(exec.object(item)); err != nil {
 				return err
 			}
 		}
@@ -174,22 +233,301 @@ func (exec *StormPerf) PutBulk(items []*models.Entity) (err error) {
 	})
 }
 
-func (exec *StormPerf) ReadAll() ([]*models.Entity, error) {
+// read calls fn with a pointer to a slice of the current model's type to be filled, and returns the slice contents
+func (exec *StormPerf) read(fn func(to interface{}) error) ([]*models.Entity, error) {
+	if exec.indexed {
+		var items []*models.IndexedEntity
+		if err := fn(&items); err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+		return models.FromIndexed(items), nil
+	}
+
 	var items []*models.Entity
-	if err := exec.db.All(&items); err != nil {
+	if err := fn(&items); err != nil && err != storm.ErrNotFound {
 		return nil, err
 	}
 	return items, nil
 }
 
+func (exec *StormPerf) ReadAll() ([]*models.Entity, error) {
+	return exec.read(func(to interface{}) error {
+		return exec.db.All(to)
+	})
+}
+
+func (exec *StormPerf) ReadChunk(afterId uint64, limit int) ([]*models.Entity, error) {
+	// Range() on the ID uses its index, unlike a query with the same conditions which would iterate from the start
+	return exec.read(func(to interface{}) error {
+		return exec.db.Range("Id", afterId+1, uint64(math.MaxUint64), to, storm.Limit(limit))
+	})
+}
+
+// find executes the query, reading the results as the type of the current model
+func (exec *StormPerf) find(query storm.Query) ([]*models.Entity, error) {
+	return exec.read(query.Find)
+}
+
 func (exec *StormPerf) QueryIdBetween(min, max uint64) ([]*models.Entity, error) {
-	var items []*models.Entity
-	var err = exec.db.Select(q.Gte("Id", min), q.Lte("Id", max)).Find(&items)
-	return items, err
+	return exec.find(exec.db.Select(q.Gte("Id", min), q.Lte("Id", max)))
+}
+
+func (exec *StormPerf) GetMany(ids []uint64) ([]*models.Entity, error) {
+	// there's no multi-key lookup in storm, the objects are read one by one in a single read transaction
+	tx, err := exec.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var items = make([]*models.Entity, 0, len(ids))
+	for _, id := range ids {
+		var item = &models.Entity{}
+		if err := tx.One("Id", id, exec.object(item)); err == storm.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
 }
 
 func (exec *StormPerf) QueryStringPrefix(prefix string) ([]*models.Entity, error) {
-	var items []*models.Entity
-	var err = exec.db.Prefix("String", prefix, &items)
-	return items, err
+	return exec.read(func(to interface{}) error {
+		return exec.db.Prefix("String", prefix, to)
+	})
+}
+
+func (exec *StormPerf) QueryStringPrefixCaseInsensitive(prefix string) ([]*models.Entity, error) {
+	return exec.find(exec.db.Select(q.Re("String", "(?i)^"+regexp.QuoteMeta(prefix))))
+}
+
+func (exec *StormPerf) QueryStringContains(text string) ([]*models.Entity, error) {
+	return exec.find(exec.db.Select(q.Re("String", regexp.QuoteMeta(text))))
+}
+
+func (exec *StormPerf) QueryInt32Equal(value int32) ([]*models.Entity, error) {
+	// storm doesn't index zero values so Find() wouldn't return them, use a query which doesn't use the index instead
+	if value == 0 {
+		return exec.find(exec.db.Select(q.Eq("Int32", value)))
+	}
+
+	// Find() uses the index, if present
+	return exec.read(func(to interface{}) error {
+		return exec.db.Find("Int32", value, to)
+	})
+}
+
+func (exec *StormPerf) QueryInt64Greater(value int64) ([]*models.Entity, error) {
+	return exec.find(exec.db.Select(q.Gt("Int64", value)))
+}
+
+func (exec *StormPerf) QueryFloat64Between(min, max float64) ([]*models.Entity, error) {
+	return exec.read(func(to interface{}) error {
+		return exec.db.Range("Float64", min, max, to)
+	})
+}
+
+func (exec *StormPerf) QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64) ([]*models.Entity,
+	error) {
+	return exec.find(exec.db.Select(q.And(q.Eq("Int32", int32Value), q.Gt("Int64", int64Value))))
+}
+
+func (exec *StormPerf) QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64) ([]*models.Entity,
+	error) {
+	return exec.find(exec.db.Select(q.Or(q.Eq("Int32", int32Value), q.And(q.Gte("Float64", min),
+		q.Lte("Float64", max)))))
+}
+
+func (exec *StormPerf) QueryInt64Ordered(descending bool, offset, limit int) ([]*models.Entity, error) {
+	// order by ID as well to make the order stable for objects with the same Int64 value
+	var query = exec.db.Select().OrderBy("Int64", "Id")
+	if descending {
+		query = query.Reverse()
+	}
+	return exec.find(query.Skip(offset).Limit(limit))
+}
+
+func (exec *StormPerf) Get(id uint64) (*models.Entity, error) {
+	var item = &models.Entity{}
+	if err := exec.db.One("Id", id, exec.object(item)); err == storm.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (exec *StormPerf) Put(item *models.Entity) error {
+	return exec.db.Save(exec.object(item))
+}
+
+func (exec *StormPerf) Remove(item *models.Entity) error {
+	return exec.db.DeleteStruct(exec.object(item))
+}
+
+func (exec *StormPerf) Count() (uint64, error) {
+	count, err := exec.db.Count(exec.object(&models.Entity{}))
+	return uint64(count), err
+}
+
+func (exec *StormPerf) CountInt64Greater(value int64) (uint64, error) {
+	count, err := exec.db.Select(q.Gt("Int64", value)).Count(exec.object(&models.Entity{}))
+	return uint64(count), err
+}
+
+// each calls fn for each object matching the query, as there are no aggregate functions in storm
+func (exec *StormPerf) each(query storm.Query, fn func(object *models.Entity)) error {
+	var err = query.Each(exec.object(&models.Entity{}), func(record interface{}) error {
+		if exec.indexed {
+			fn((*models.Entity)(record.(*models.IndexedEntity)))
+		} else {
+			fn(record.(*models.Entity))
+		}
+		return nil
+	})
+
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (exec *StormPerf) aggregateInt64(query storm.Query) (perf.Int64Aggregates, error) {
+	var result perf.Int64Aggregates
+	var err = exec.each(query, func(object *models.Entity) {
+		if result.Count == 0 || object.Int64 < result.Min {
+			result.Min = object.Int64
+		}
+		if result.Count == 0 || object.Int64 > result.Max {
+			result.Max = object.Int64
+		}
+		result.Count++
+		result.Sum += object.Int64
+	})
+
+	if result.Count > 0 {
+		result.Average = float64(result.Sum) / float64(result.Count)
+	}
+	return result, err
+}
+
+func (exec *StormPerf) aggregateFloat64(query storm.Query) (perf.Float64Aggregates, error) {
+	var result perf.Float64Aggregates
+	var err = exec.each(query, func(object *models.Entity) {
+		if result.Count == 0 || object.Float64 < result.Min {
+			result.Min = object.Float64
+		}
+		if result.Count == 0 || object.Float64 > result.Max {
+			result.Max = object.Float64
+		}
+		result.Count++
+		result.Sum += object.Float64
+	})
+
+	if result.Count > 0 {
+		result.Average = result.Sum / float64(result.Count)
+	}
+	return result, err
+}
+
+func (exec *StormPerf) AggregateInt64() (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.db.Select())
+}
+
+func (exec *StormPerf) AggregateInt64Greater(value int64) (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.db.Select(q.Gt("Int64", value)))
+}
+
+func (exec *StormPerf) AggregateFloat64() (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.db.Select())
+}
+
+func (exec *StormPerf) AggregateFloat64Between(min, max float64) (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.db.Select(q.Gte("Float64", min), q.Lte("Float64", max)))
+}
+
+func (exec *StormPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.runInTx(func(tx storm.Node) error {
+		for _, customer := range customers {
+			if err := tx.Save(customer); err != nil {
+				return err
+			}
+
+			// storm doesn't support relations, the orders are linked to the customer manually using a foreign key
+			for _, order := range customer.Orders {
+				order.CustomerId = customer.Id
+				if err := tx.Save(order); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ordersOf reads the orders of the given customer using the index on the foreign key
+func ordersOf(node storm.Node, customerId uint64) ([]*models.Order, error) {
+	var orders []*models.Order
+	if err := node.Find("CustomerId", customerId, &orders); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (exec *StormPerf) ReadCustomers() ([]*models.Customer, error) {
+	tx, err := exec.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var customers []*models.Customer
+	if err := tx.All(&customers); err != nil {
+		return nil, err
+	}
+
+	for _, customer := range customers {
+		if customer.Orders, err = ordersOf(tx, customer.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	return customers, nil
+}
+
+func (exec *StormPerf) QueryOrdersByCustomerName(name string) ([]*models.Order, error) {
+	tx, err := exec.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var customers []*models.Customer
+	if err := tx.Find("Name", name, &customers); err != nil {
+		if err == storm.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []*models.Order
+	for _, customer := range customers {
+		if orders, err := ordersOf(tx, customer.Id); err != nil {
+			return nil, err
+		} else {
+			result = append(result, orders...)
+		}
+	}
+	return result, nil
+}
+
+func (exec *StormPerf) RemoveAllCustomers() error {
+	return exec.runInTx(func(tx storm.Node) error {
+		if err := tx.Select().Delete(&models.Order{}); err != nil {
+			return err
+		}
+		return tx.Select().Delete(&models.Customer{})
+	})
 }
@@ -0,0 +1,315 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/objectbox/objectbox-go-performance/internal/boltopt"
+	"github.com/objectbox/objectbox-go-performance/internal/cmd"
+	"github.com/objectbox/objectbox-go-performance/internal/kv"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	bolt "go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	var options = cmd.GetOptions()
+
+	var executable = &BoltPerf{
+		path:           options.Path,
+		durability:     options.Durability,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
+	}
+
+	var executor = perf.CreateExecutor(executable)
+	defer executor.Close()
+
+	executor.Run(options)
+}
+
+// perf executable using bbolt directly, without the reflection and the codec of storm, to see what bbolt itself costs.
+// Objects are stored in a bucket per table, using the binary encoding & the reads of the kv package.
+// The indexed model maintains an index bucket for each indexed property.
+type BoltPerf struct {
+	kv.Store
+	path           string
+	durability     string
+	backendOptions map[string]string // bolt options: page-size, initial-mmap-size, mmap-flags, freelist-type
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	db             *bolt.DB
+	tx             *bolt.Tx // used for PutAsync
+}
+
+func (exec *BoltPerf) Init() error {
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(exec.path, 0777); err != nil {
+		return err
+	}
+
+	if err := exec.Open(); err != nil {
+		return err
+	}
+
+	exec.Store.View = exec.view
+
+	if err := exec.db.Update(func(tx *bolt.Tx) error {
+		var names = append(append(kv.PlainTables.All(), kv.IndexedTables.All()...), kv.RelationTables...)
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return exec.SetModel(perf.ModelPlain)
+}
+
+// Open opens the database in the existing directory
+func (exec *BoltPerf) Open() error {
+	boltOptions, err := boltopt.Options(exec.durability, exec.backendOptions)
+	if err != nil {
+		return err
+	}
+
+	if db, err := bolt.Open(filepath.Join(exec.path, "test.db"), 0600, boltOptions); err != nil {
+		return err
+	} else {
+		exec.db = db
+	}
+	return nil
+}
+
+func (exec *BoltPerf) Release() error {
+	return exec.db.Close()
+}
+
+func (exec *BoltPerf) Close() error {
+	if err := exec.db.Close(); err != nil {
+		return err
+	}
+
+	if exec.keepData {
+		return nil
+	}
+
+	return os.RemoveAll(exec.path)
+}
+
+func (exec *BoltPerf) Size() (uint64, error) {
+	if stat, err := os.Stat(filepath.Join(exec.path, "test.db")); err != nil {
+		return 0, err
+	} else {
+		return uint64(stat.Size()), nil
+	}
+}
+
+// transactions
+
+// reader reads in a bolt transaction, each table is a bucket
+type reader struct {
+	tx *bolt.Tx
+}
+
+func (r reader) Get(table, key []byte) ([]byte, error) {
+	return r.tx.Bucket(table).Get(key), nil
+}
+
+func (r reader) Iterate(table, start []byte, reverse bool, fn func(key, value []byte) (bool, error)) error {
+	var cursor = r.tx.Bucket(table).Cursor()
+	var key, value []byte
+	if reverse {
+		key, value = cursor.Last()
+	} else if start != nil {
+		key, value = cursor.Seek(start)
+	} else {
+		key, value = cursor.First()
+	}
+
+	for key != nil {
+		if next, err := fn(key, value); err != nil {
+			return err
+		} else if !next {
+			break
+		}
+
+		if reverse {
+			key, value = cursor.Prev()
+		} else {
+			key, value = cursor.Next()
+		}
+	}
+	return nil
+}
+
+func (r reader) IterateKeys(table, start []byte, reverse bool, fn func(key []byte) (bool, error)) error {
+	return r.Iterate(table, start, reverse, func(key, _ []byte) (bool, error) {
+		return fn(key)
+	})
+}
+
+// writer writes in a read-write bolt transaction, IDs are assigned by the sequence of the bucket
+type writer struct {
+	reader
+}
+
+func (w writer) Set(table, key, value []byte) error {
+	return w.tx.Bucket(table).Put(key, value)
+}
+
+func (w writer) Delete(table, key []byte) error {
+	return w.tx.Bucket(table).Delete(key)
+}
+
+func (w writer) NextId(table []byte) (uint64, error) {
+	return w.tx.Bucket(table).NextSequence()
+}
+
+// view runs the function in a read transaction
+func (exec *BoltPerf) view(fn func(reader kv.Reader) error) error {
+	return exec.db.View(func(tx *bolt.Tx) error {
+		return fn(reader{tx})
+	})
+}
+
+// update runs the function in a read-write transaction
+func (exec *BoltPerf) update(fn func(writer kv.Writer) error) error {
+	return exec.db.Update(func(tx *bolt.Tx) error {
+		return fn(writer{reader{tx}})
+	})
+}
+
+// writing
+
+// recreateBuckets removes all data by deleting the buckets and creating them again
+func recreateBuckets(tx *bolt.Tx, names [][]byte) error {
+	for _, name := range names {
+		if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (exec *BoltPerf) RemoveAll() error {
+	return exec.db.Update(func(tx *bolt.Tx) error {
+		return recreateBuckets(tx, exec.Tables.All())
+	})
+}
+
+func (exec *BoltPerf) RemoveBulk(items []*models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		for _, object := range items {
+			if err := kv.Remove(writer, exec.Tables, object.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *BoltPerf) PutAsync(item *models.Entity) error {
+	// PutAsync is simulated by reusing a transaction and committing it afterwards
+	if exec.tx == nil {
+		if tx, err := exec.db.Begin(true); err != nil {
+			return err
+		} else {
+			exec.tx = tx
+		}
+	}
+
+	if err := kv.Put(writer{reader{exec.tx}}, exec.Tables, item); err != nil {
+		if err2 := exec.tx.Rollback(); err2 != nil {
+			panic(err2)
+		}
+		exec.tx = nil
+		return err
+	}
+
+	return nil
+}
+
+func (exec *BoltPerf) AwaitAsyncCompletion() error {
+	if exec.tx != nil {
+		var err = exec.tx.Commit()
+		exec.tx = nil
+		return err
+	}
+
+	return nil
+}
+
+func (exec *BoltPerf) PutBulk(items []*models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		for _, item := range items {
+			if err := kv.Put(writer, exec.Tables, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *BoltPerf) Put(item *models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		return kv.Put(writer, exec.Tables, item)
+	})
+}
+
+func (exec *BoltPerf) Remove(item *models.Entity) error {
+	return exec.update(func(writer kv.Writer) error {
+		return kv.Remove(writer, exec.Tables, item.Id)
+	})
+}
+
+// Count uses the bucket statistics instead of iterating over the keys
+func (exec *BoltPerf) Count() (uint64, error) {
+	var count uint64
+	var err = exec.db.View(func(tx *bolt.Tx) error {
+		count = uint64(tx.Bucket(exec.Tables.Data).Stats().KeyN)
+		return nil
+	})
+	return count, err
+}
+
+// relations
+
+func (exec *BoltPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.update(func(writer kv.Writer) error {
+		return kv.PutCustomers(writer, customers)
+	})
+}
+
+func (exec *BoltPerf) RemoveAllCustomers() error {
+	return exec.db.Update(func(tx *bolt.Tx) error {
+		return recreateBuckets(tx, kv.RelationTables)
+	})
+}
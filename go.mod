@@ -7,14 +7,15 @@ require (
 	github.com/DataDog/zstd v1.4.0 // indirect
 	github.com/Sereal/Sereal v0.0.0-20190618215532-0b8ac451a863 // indirect
 	github.com/asdine/storm v0.0.0-20190418133842-e0f77eada154
+	github.com/dgraph-io/badger v1.6.2
 	github.com/golang/snappy v0.0.1 // indirect
 	github.com/google/flatbuffers v23.5.26+incompatible
 	github.com/jinzhu/gorm v1.9.10
-	github.com/kr/pretty v0.1.0 // indirect
+	github.com/mattn/go-sqlite3 v1.10.0
 	github.com/objectbox/objectbox-go v1.9.0
 	github.com/pkg/profile v1.3.0
-	github.com/stretchr/testify v1.3.0 // indirect
+	github.com/syndtr/goleveldb v1.0.0
 	github.com/vmihailenco/msgpack v4.0.4+incompatible // indirect
-	go.etcd.io/bbolt v1.3.3 // indirect
+	go.etcd.io/bbolt v1.3.3
 	golang.org/x/crypto v0.31.0 // indirect
 )
@@ -17,21 +17,30 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/mattn/go-sqlite3"
 	"github.com/objectbox/objectbox-go-performance/internal/cmd"
 	"github.com/objectbox/objectbox-go-performance/internal/models"
 	"github.com/objectbox/objectbox-go-performance/internal/perf"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 func main() {
 	var options = cmd.GetOptions()
 
 	var executable = &GormPerf{
-		path: options.Path,
+		path:           options.Path,
+		durability:     options.Durability,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
 	}
 
 	var executor = perf.CreateExecutor(executable)
@@ -42,30 +51,123 @@ func main() {
 
 // perf executable
 type GormPerf struct {
-	path string
-	db   *gorm.DB
-	tx   *gorm.DB // used for PutAsync
+	path           string
+	durability     string
+	backendOptions map[string]string // SQLite pragmas set on each connection, e.g. cache_size or mmap_size
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	db             *gorm.DB
+	tx             *gorm.DB // used for PutAsync
+	table          string   // name of the table of the current model
+}
+
+// pragmaRegexp restricts the backend options, which are used as pragma names & values in SQL statements
+var pragmaRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// sqliteConnector opens connections executing the pragmas given as backend options on each of them
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func (connector *sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	return connector.driver.Open(connector.dsn)
+}
+
+func (connector *sqliteConnector) Driver() driver.Driver {
+	return connector.driver
+}
 
+// connectionParams maps the durability level to SQLite journal_mode & synchronous pragmas, set on each connection
+func (exec *GormPerf) connectionParams() (string, error) {
+	switch exec.durability {
+	case perf.DurabilityFull:
+		return "_journal_mode=DELETE&_synchronous=FULL", nil
+	case perf.DurabilityNormal:
+		return "_journal_mode=WAL&_synchronous=NORMAL", nil
+	case perf.DurabilityNone:
+		return "_journal_mode=MEMORY&_synchronous=OFF", nil
+	default:
+		return "", fmt.Errorf("unknown durability level %s", exec.durability)
+	}
 }
 
 func (exec *GormPerf) Init() error {
-	if err := os.RemoveAll(exec.path); err != nil {
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(exec.path, 0777); err != nil {
+		return err
+	}
+
+	if err := exec.Open(); err != nil {
+		return err
+	}
+
+	if err := exec.db.AutoMigrate(&models.Entity{}, &models.IndexedEntity{}, &models.Customer{},
+		&models.Order{}).Error; err != nil {
 		return err
 	}
 
-	if err := os.Mkdir(exec.path, 0777); err != nil {
+	return exec.SetModel(perf.ModelPlain)
+}
+
+// Open opens the database in the existing directory
+func (exec *GormPerf) Open() error {
+	params, err := exec.connectionParams()
+	if err != nil {
 		return err
 	}
 
-	if db, err := gorm.Open("sqlite3", filepath.Join(exec.path, "test.db")); err != nil {
+	for key, value := range exec.backendOptions {
+		if !pragmaRegexp.MatchString(key) || !pragmaRegexp.MatchString(value) {
+			return fmt.Errorf("invalid SQLite pragma %s=%s", key, value)
+		}
+	}
+
+	// the busy timeout (the driver's default, made explicit here) makes SQLite wait up to 5 seconds for a lock held
+	// by another connection instead of failing with SQLITE_BUSY, i.e. concurrent writers are serialized and the mixed
+	// workload test reports the waiting as latency, not as errors
+	var connector = &sqliteConnector{
+		dsn: filepath.Join(exec.path, "test.db") + "?" + params + "&_busy_timeout=5000",
+		driver: &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				for key, value := range exec.backendOptions {
+					if _, err := conn.Exec(fmt.Sprintf("PRAGMA %s = %s", key, value), nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	if db, err := gorm.Open("sqlite3", sql.OpenDB(connector)); err != nil {
 		return err
 	} else {
 		exec.db = db
 	}
+	return nil
+}
 
-	exec.db.AutoMigrate(&models.Entity{})
+func (exec *GormPerf) Release() error {
+	return exec.db.Close()
+}
 
-	return exec.db.Error
+func (exec *GormPerf) SetModel(model string) error {
+	// all operations work with models.Entity, the table decides whether the indexed model is used
+	switch model {
+	case perf.ModelPlain:
+		exec.table = exec.db.NewScope(&models.Entity{}).TableName()
+	case perf.ModelIndexed:
+		exec.table = exec.db.NewScope(&models.IndexedEntity{}).TableName()
+	default:
+		return fmt.Errorf("unknown model %s", model)
+	}
+	return nil
 }
 
 func (exec *GormPerf) Close() error {
@@ -73,27 +175,39 @@ func (exec *GormPerf) Close() error {
 		return err
 	}
 
-	if err := os.RemoveAll(exec.path); err != nil {
-		return err
+	if exec.keepData {
+		return nil
 	}
 
-	return nil
+	return os.RemoveAll(exec.path)
 }
 
 func (exec *GormPerf) Size() (uint64, error) {
+	var size uint64
 	if stat, err := os.Stat(filepath.Join(exec.path, "test.db")); err != nil {
 		return 0, err
 	} else {
-		return uint64(stat.Size()), nil
+		size = uint64(stat.Size())
+	}
+
+	// write-ahead log, only present with the normal durability level
+	if stat, err := os.Stat(filepath.Join(exec.path, "test.db-wal")); err == nil {
+		size += uint64(stat.Size())
+	} else if !os.IsNotExist(err) {
+		return 0, err
 	}
+
+	return size, nil
 }
 
 func (exec *GormPerf) RemoveAll() error {
-	return exec.db.Delete(models.Entity{}).Error
+	return exec.db.Table(exec.table).Delete(models.Entity{}).Error
 }
 
 func (exec *GormPerf) RemoveBulk(items []*models.Entity) error {
 	return exec.runInTx(func(tx *gorm.DB) error {
+		tx = tx.Table(exec.table)
+
 		// sqlite takes at most 999 variables by default, see SQLITE_MAX_VARIABLE_NUMBER
 		// if we pass more, we get an error "too many sql variables"
 		// therefore, we're running a delete for at most 999 items at a time
@@ -121,7 +235,7 @@ func (exec *GormPerf) RemoveBulk(items []*models.Entity) error {
 func (exec *GormPerf) PutAsync(item *models.Entity) error {
 	// PutAsync is simulated by reusing a transaction and committing it afterwards
 	if exec.tx == nil {
-		exec.tx = exec.db.Begin().Model(&models.Entity{})
+		exec.tx = exec.db.Begin().Table(exec.table)
 	}
 
 	if item.Id == 0 {
@@ -152,7 +266,7 @@ func (exec *GormPerf) AwaitAsyncCompletion() error {
 // run a callback in a transaction
 func (exec *GormPerf) runInTx(fn func(tx *gorm.DB) error) (err error) {
 	// see http://gorm.io/docs/transactions.html
-	tx := exec.db.Begin().Model(&models.Entity{})
+	tx := exec.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -183,6 +297,7 @@ func (exec *GormPerf) PutBulk(items []*models.Entity) (err error) {
 	// is implemented, we're using manual transactions
 
 	return exec.runInTx(func(tx *gorm.DB) error {
+		tx = tx.Table(exec.table)
 		for _, item := range items {
 			if item.Id == 0 {
 				tx.Create(item)
@@ -199,19 +314,225 @@ func (exec *GormPerf) PutBulk(items []*models.Entity) (err error) {
 
 func (exec *GormPerf) ReadAll() ([]*models.Entity, error) {
 	var items []*models.Entity
-	exec.db.Find(&items)
-	return items, exec.db.Error
+	var err = exec.db.Table(exec.table).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) ReadChunk(afterId uint64, limit int) ([]*models.Entity, error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Id > ?", afterId).Order("Id ASC").Limit(limit).Find(&items).Error
+	return items, err
 }
 
 func (exec *GormPerf) QueryIdBetween(min, max uint64) ([]*models.Entity, error) {
 	var items []*models.Entity
-	exec.db.Where("Id BETWEEN ? AND ?", min, max).Find(&items)
-	return items, exec.db.Error
+	var err = exec.db.Table(exec.table).Where("Id BETWEEN ? AND ?", min, max).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) GetMany(ids []uint64) ([]*models.Entity, error) {
+	var items = make([]*models.Entity, 0, len(ids))
+
+	// sqlite takes at most 999 variables by default, see SQLITE_MAX_VARIABLE_NUMBER in RemoveBulk()
+	const limit = 999
+	for i := 0; i < len(ids); i += limit {
+		var end = i + limit
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var chunk []*models.Entity
+		if err := exec.db.Table(exec.table).Where("Id IN (?)", ids[i:end]).Find(&chunk).Error; err != nil {
+			return nil, err
+		}
+		items = append(items, chunk...)
+	}
+	return items, nil
 }
 
 func (exec *GormPerf) QueryStringPrefix(prefix string) ([]*models.Entity, error) {
 	var items []*models.Entity
-	// NOTE this doesn't work correctly if `prefix` contains "%"
-	exec.db.Where("String LIKE ?", prefix+"%").Find(&items)
-	return items, exec.db.Error
+	// NOTE this doesn't work correctly if `prefix` contains "*", "?" or "["
+	// GLOB is used instead of LIKE because it's case-sensitive and SQLite can therefore use the index (if present)
+	var err = exec.db.Table(exec.table).Where("String GLOB ?", prefix+"*").Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryStringPrefixCaseInsensitive(prefix string) ([]*models.Entity, error) {
+	var items []*models.Entity
+	// NOTE this doesn't work correctly if `prefix` contains "%" or "_"
+	// LIKE is case-insensitive for ASCII characters
+	var err = exec.db.Table(exec.table).Where("String LIKE ?", prefix+"%").Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryStringContains(text string) ([]*models.Entity, error) {
+	var items []*models.Entity
+	// instr() is case-sensitive, as opposed to LIKE
+	var err = exec.db.Table(exec.table).Where("instr(String, ?) > 0", text).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryInt32Equal(value int32) ([]*models.Entity, error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Int32 = ?", value).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryInt64Greater(value int64) ([]*models.Entity, error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Int64 > ?", value).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryFloat64Between(min, max float64) ([]*models.Entity, error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Float64 BETWEEN ? AND ?", min, max).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64) ([]*models.Entity,
+	error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Int32 = ? AND Int64 > ?", int32Value, int64Value).
+		Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64) ([]*models.Entity,
+	error) {
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Where("Int32 = ? OR Float64 BETWEEN ? AND ?", int32Value, min, max).
+		Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) QueryInt64Ordered(descending bool, offset, limit int) ([]*models.Entity, error) {
+	// order by ID as well to make the order stable for objects with the same Int64 value
+	var order = "Int64 ASC, Id ASC"
+	if descending {
+		order = "Int64 DESC, Id DESC"
+	}
+
+	var items []*models.Entity
+	var err = exec.db.Table(exec.table).Order(order).Offset(offset).Limit(limit).Find(&items).Error
+	return items, err
+}
+
+func (exec *GormPerf) Get(id uint64) (*models.Entity, error) {
+	var item models.Entity
+	if err := exec.db.Table(exec.table).Where("Id = ?", id).Take(&item).Error; gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (exec *GormPerf) Put(item *models.Entity) error {
+	// both Create() and Save() run in a transaction on their own
+	if item.Id == 0 {
+		return exec.db.Table(exec.table).Create(item).Error
+	}
+	return exec.db.Table(exec.table).Save(item).Error
+}
+
+func (exec *GormPerf) Remove(item *models.Entity) error {
+	return exec.db.Table(exec.table).Delete(item).Error
+}
+
+func (exec *GormPerf) Count() (count uint64, err error) {
+	err = exec.db.Table(exec.table).Count(&count).Error
+	return count, err
+}
+
+func (exec *GormPerf) CountInt64Greater(value int64) (count uint64, err error) {
+	err = exec.db.Table(exec.table).Where("Int64 > ?", value).Count(&count).Error
+	return count, err
+}
+
+// aggregates returns a query selecting all the aggregate functions over the given column
+func aggregates(db *gorm.DB, column string) *gorm.DB {
+	// COALESCE() because SUM() returns NULL for an empty set (which can't be read into an int)
+	return db.Select(fmt.Sprintf("COUNT(%[1]s), COALESCE(SUM(%[1]s), 0), COALESCE(MIN(%[1]s), 0), "+
+		"COALESCE(MAX(%[1]s), 0), COALESCE(AVG(%[1]s), 0)", column))
+}
+
+func (exec *GormPerf) aggregateInt64(db *gorm.DB) (result perf.Int64Aggregates, err error) {
+	err = aggregates(db, "Int64").Row().Scan(&result.Count, &result.Sum, &result.Min, &result.Max, &result.Average)
+	return result, err
+}
+
+func (exec *GormPerf) aggregateFloat64(db *gorm.DB) (result perf.Float64Aggregates, err error) {
+	err = aggregates(db, "Float64").Row().Scan(&result.Count, &result.Sum, &result.Min, &result.Max,
+		&result.Average)
+	return result, err
+}
+
+func (exec *GormPerf) AggregateInt64() (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.db.Table(exec.table))
+}
+
+func (exec *GormPerf) AggregateInt64Greater(value int64) (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.db.Table(exec.table).Where("Int64 > ?", value))
+}
+
+func (exec *GormPerf) AggregateFloat64() (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.db.Table(exec.table))
+}
+
+func (exec *GormPerf) AggregateFloat64Between(min, max float64) (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.db.Table(exec.table).Where("Float64 BETWEEN ? AND ?", min, max))
+}
+
+func (exec *GormPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.runInTx(func(tx *gorm.DB) error {
+		for _, customer := range customers {
+			// the orders are inserted as well, with their CustomerId set, as part of the has-many association
+			if err := tx.Create(customer).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *GormPerf) ReadCustomers() ([]*models.Customer, error) {
+	// sqlite takes at most 999 variables by default, see SQLITE_MAX_VARIABLE_NUMBER
+	// because Preload() passes all customer IDs to a single query, we're reading at most 999 customers at a time
+	const limit = 999
+	var customers []*models.Customer
+	for {
+		var lastId uint64
+		if len(customers) > 0 {
+			lastId = customers[len(customers)-1].Id
+		}
+
+		var chunk []*models.Customer
+		var err = exec.db.Preload("Orders").Where("id > ?", lastId).Order("id").Limit(limit).Find(&chunk).Error
+		if err != nil {
+			return nil, err
+		}
+
+		customers = append(customers, chunk...)
+		if len(chunk) < limit {
+			return customers, nil
+		}
+	}
+}
+
+func (exec *GormPerf) QueryOrdersByCustomerName(name string) ([]*models.Order, error) {
+	var orders []*models.Order
+	var err = exec.db.Joins("JOIN customers ON customers.id = orders.customer_id").
+		Where("customers.name = ?", name).Find(&orders).Error
+	return orders, err
+}
+
+func (exec *GormPerf) RemoveAllCustomers() error {
+	return exec.runInTx(func(tx *gorm.DB) error {
+		if err := tx.Delete(models.Order{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(models.Customer{}).Error
+	})
 }
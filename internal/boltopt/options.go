@@ -0,0 +1,72 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package boltopt maps the durability level and the backend options to bbolt options, shared by the executables
+// using bbolt: bolt and bolt-storm.
+package boltopt
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	bolt "go.etcd.io/bbolt"
+	"strconv"
+)
+
+// Options maps the durability level and the backend options to bolt options
+func Options(durability string, backendOptions map[string]string) (*bolt.Options, error) {
+	var options = *bolt.DefaultOptions
+	switch durability {
+	case perf.DurabilityFull:
+	case perf.DurabilityNormal:
+		// commits are written to the file without fsync, so they're in the OS cache when the process crashes; the
+		// freelist is rebuilt on open instead of being written on each commit
+		options.NoSync = true
+		options.NoFreelistSync = true
+	case perf.DurabilityNone:
+		// additionally, growing the file isn't synced either
+		options.NoSync = true
+		options.NoFreelistSync = true
+		options.NoGrowSync = true
+	default:
+		return nil, fmt.Errorf("unknown durability level %s", durability)
+	}
+
+	for key, value := range backendOptions {
+		var err error
+		switch key {
+		case "page-size":
+			options.PageSize, err = strconv.Atoi(value)
+		case "initial-mmap-size":
+			options.InitialMmapSize, err = strconv.Atoi(value)
+		case "mmap-flags":
+			var flags int64
+			flags, err = strconv.ParseInt(value, 0, 0) // accepts hexadecimal, e.g. 0x8000 for MAP_POPULATE on Linux
+			options.MmapFlags = int(flags)
+		case "freelist-type":
+			if value != string(bolt.FreelistArrayType) && value != string(bolt.FreelistMapType) {
+				err = fmt.Errorf("expected %s or %s", bolt.FreelistArrayType, bolt.FreelistMapType)
+			}
+			options.FreelistType = bolt.FreelistType(value)
+		default:
+			err = fmt.Errorf("unknown option, expected one of page-size, initial-mmap-size, mmap-flags, freelist-type")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backend option %s=%s: %s", key, value, err)
+		}
+	}
+
+	return &options, nil
+}
@@ -18,7 +18,11 @@ package cmd
 
 import (
 	"flag"
+	"fmt"
 	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 func GetOptions() perf.Options {
@@ -30,7 +34,152 @@ func GetOptions() perf.Options {
 	flag.IntVar(&o.Runs, "runs", o.Runs, "number of times the tests should be executed")
 	flag.BoolVar(&o.Profile, "profile", o.Profile, "enable profiling")
 	flag.BoolVar(&o.ManualGc, "disable-gc", o.ManualGc, "disable garbage collection")
+	flag.StringVar(&o.Format, "format", o.Format, "output format: table or benchstat")
+	flag.Int64Var(&o.Seed, "seed", o.Seed, "random seed for test data generation")
+	flag.StringVar(&o.Distribution, "distribution", o.Distribution,
+		"distribution of numeric values: sequential, uniform, normal or zipfian")
+	flag.StringVar(&o.StringDistribution, "string-distribution", o.StringDistribution,
+		"distribution of string and byte-slice lengths: sequential, uniform, normal or zipfian")
+	flag.IntVar(&o.StringMinLength, "string-min", o.StringMinLength, "minimum length of generated strings")
+	flag.IntVar(&o.StringMaxLength, "string-max", o.StringMaxLength, "maximum length of generated strings")
+	flag.IntVar(&o.BytesMinLength, "bytes-min", o.BytesMinLength, "minimum length of generated byte-slice payloads")
+	flag.IntVar(&o.BytesMaxLength, "bytes-max", o.BytesMaxLength, "maximum length of generated byte-slice payloads")
+	flag.Var((*intList)(&o.BytesSweep), "bytes-sweep",
+		"comma-separated byte-slice payload lengths to run the tests with, e.g. 0,1024,16384")
+	flag.Var((*stringList)(&o.Models), "models", "comma-separated entity models to run the tests with: plain, indexed")
+	flag.IntVar(&o.OrdersPerCustomer, "orders", o.OrdersPerCustomer,
+		"run the relations test with this number of orders per customer; 0 to skip the relations test")
+	flag.IntVar(&o.PageSize, "page-size", o.PageSize, "number of objects per page when paging through ordered results")
+	flag.IntVar(&o.SingleOps, "single-ops", o.SingleOps,
+		"number of objects to put, get and remove one by one, each in its own transaction; 0 to skip")
+	flag.Var((*intList)(&o.GetManySizes), "get-many-sizes",
+		"comma-separated numbers of IDs to look up at once; empty to skip")
+	flag.StringVar(&o.GetManySelection, "get-many-selection", o.GetManySelection,
+		"how the looked up IDs are selected: random or sequential")
+	flag.IntVar(&o.Readers, "readers", o.Readers, "run the concurrent readers test with 1, 2, 4, ... up to this "+
+		"number of reader goroutines, GOMAXPROCS by default; 0 to skip the concurrent readers test")
+	flag.DurationVar(&o.ConcurrentDuration, "concurrent-duration", o.ConcurrentDuration,
+		"duration of each phase of the concurrent tests")
+	flag.IntVar(&o.MixedWorkers, "mixed-workers", o.MixedWorkers,
+		"run the mixed read/write workload test with this number of goroutines; 0 to skip the mixed workload test")
+	flag.Float64Var(&o.ReadRatio, "read-ratio", o.ReadRatio,
+		"fraction of the operations of the mixed workload test which are reads, the rest are writes")
+	flag.Var((*stringList)(&o.YcsbWorkloads), "ycsb",
+		"comma-separated YCSB core workloads to run, e.g. A,B,C,D,E,F; empty to skip the YCSB test")
+	flag.IntVar(&o.YcsbOperations, "ycsb-operations", o.YcsbOperations,
+		"number of operations of the run phase of each YCSB workload")
+	flag.IntVar(&o.YcsbThreads, "ycsb-threads", o.YcsbThreads, "number of goroutines executing the YCSB workloads")
+	flag.IntVar(&o.ScaleCount, "scale", o.ScaleCount, "run only the large dataset test with this number of objects, "+
+		"generated, inserted and read in chunks so that they don't need to fit in memory; 0 to run the other tests")
+	flag.IntVar(&o.ChunkSize, "chunk-size", o.ChunkSize,
+		"number of objects generated, inserted and read at once in the large dataset test")
+	flag.IntVar(&o.BatchSize, "batch-size", o.BatchSize,
+		"number of objects inserted in a single transaction by PutBulk; 0 to insert all objects at once")
+	flag.Var((*intList)(&o.BatchSweep), "batch-sweep", "comma-separated batch sizes to insert the objects with, "+
+		"e.g. 1,10,100,1000,10000; empty to skip the batch size sweep test")
+	flag.StringVar(&o.Durability, "durability", o.Durability,
+		"durability level, mapped to the native settings of each database: full, normal or none")
+	flag.Var((*keyValueMap)(&o.BackendOptions), "backend-opt", "database specific open option as key=value, "+
+		"can be repeated; see README.md for the options supported by each database")
+	flag.IntVar(&o.CrashIterations, "crash", o.CrashIterations, "run the crash consistency test, killing the "+
+		"writing child process this number of times; 0 to skip the crash consistency test")
+	flag.DurationVar(&o.CrashMaxDelay, "crash-max-delay", o.CrashMaxDelay,
+		"maximum time after which the child process of the crash consistency test is killed")
+	flag.BoolVar(&o.CrashChild, "crash-child", o.CrashChild,
+		"run as the child process of the crash consistency test (used internally)")
+	flag.BoolVar(&o.KeepData, "keep-data", o.KeepData, "keep the database directory after the run instead of removing it")
+	flag.BoolVar(&o.Existing, "existing", o.Existing, "run only the existing database test: open the database "+
+		"in the -db directory without removing it, populating it with -count objects if it's empty; implies -keep-data")
+	flag.IntVar(&o.GrowthSteps, "growth", o.GrowthSteps, "run the growth profile test, inserting the objects in "+
+		"this number of steps and recording the database size after each; 0 to skip the growth profile test")
 	flag.Parse()
 
+	// the existing database test must never remove the database it was pointed at
+	if o.Existing {
+		o.KeepData = true
+	}
+
 	return o
 }
+
+// intList is a flag.Value accepting a comma-separated list of integers
+type intList []int
+
+func (list *intList) String() string {
+	if list == nil {
+		return ""
+	}
+
+	var values = make([]string, len(*list))
+	for i, value := range *list {
+		values[i] = strconv.Itoa(value)
+	}
+	return strings.Join(values, ",")
+}
+
+func (list *intList) Set(value string) error {
+	*list = nil
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	for _, str := range strings.Split(value, ",") {
+		if number, err := strconv.Atoi(strings.TrimSpace(str)); err != nil {
+			return err
+		} else {
+			*list = append(*list, number)
+		}
+	}
+	return nil
+}
+
+// stringList is a flag.Value accepting a comma-separated list of strings
+type stringList []string
+
+func (list *stringList) String() string {
+	if list == nil {
+		return ""
+	}
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = nil
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	for _, str := range strings.Split(value, ",") {
+		*list = append(*list, strings.TrimSpace(str))
+	}
+	return nil
+}
+
+// keyValueMap is a flag.Value accepting a key=value pair, collecting all the pairs if the flag is repeated
+type keyValueMap map[string]string
+
+func (values *keyValueMap) String() string {
+	if values == nil {
+		return ""
+	}
+
+	var pairs []string
+	for key, value := range *values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (values *keyValueMap) Set(pair string) error {
+	var parts = strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+
+	if *values == nil {
+		*values = map[string]string{}
+	}
+	(*values)[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	return nil
+}
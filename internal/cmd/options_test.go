@@ -0,0 +1,122 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntList(t *testing.T) {
+	var tests = []struct {
+		value    string
+		expected intList
+		str      string
+		err      bool
+	}{
+		{"", nil, "", false},
+		{" ", nil, "", false},
+		{"1", intList{1}, "1", false},
+		{"1,10,100", intList{1, 10, 100}, "1,10,100", false},
+		{" 1 , -2 ", intList{1, -2}, "1,-2", false},
+		{"1,x", nil, "", true},
+		{"1,,2", nil, "", true},
+	}
+
+	for _, test := range tests {
+		var list = intList{42}
+		var err = list.Set(test.value)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.value)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%q: unexpected error %s", test.value, err)
+		} else if !reflect.DeepEqual(list, test.expected) {
+			t.Errorf("%q: got %v instead of %v", test.value, list, test.expected)
+		} else if str := list.String(); str != test.str {
+			t.Errorf("%q: formatted as %q instead of %q", test.value, str, test.str)
+		}
+	}
+}
+
+func TestStringList(t *testing.T) {
+	var tests = []struct {
+		value    string
+		expected stringList
+		str      string
+	}{
+		{"", nil, ""},
+		{" ", nil, ""},
+		{"A", stringList{"A"}, "A"},
+		{"A,E,F", stringList{"A", "E", "F"}, "A,E,F"},
+		{" plain , indexed ", stringList{"plain", "indexed"}, "plain,indexed"},
+	}
+
+	for _, test := range tests {
+		var list = stringList{"x"}
+		if err := list.Set(test.value); err != nil {
+			t.Errorf("%q: unexpected error %s", test.value, err)
+		} else if !reflect.DeepEqual(list, test.expected) {
+			t.Errorf("%q: got %v instead of %v", test.value, list, test.expected)
+		} else if str := list.String(); str != test.str {
+			t.Errorf("%q: formatted as %q instead of %q", test.value, str, test.str)
+		}
+	}
+}
+
+func TestKeyValueMap(t *testing.T) {
+	var tests = []struct {
+		pairs    []string
+		expected keyValueMap
+		str      string
+		err      bool
+	}{
+		{nil, nil, "", false},
+		{[]string{"a=1"}, keyValueMap{"a": "1"}, "a=1", false},
+		{[]string{"b=2", "a=1"}, keyValueMap{"a": "1", "b": "2"}, "a=1,b=2", false},
+		{[]string{"a=1", "a=2"}, keyValueMap{"a": "2"}, "a=2", false},
+		{[]string{" a = x=y "}, keyValueMap{"a": "x=y"}, "a=x=y", false},
+		{[]string{"a="}, keyValueMap{"a": ""}, "a=", false},
+		{[]string{"a"}, nil, "", true},
+		{[]string{"=1"}, nil, "", true},
+	}
+
+	for _, test := range tests {
+		var values keyValueMap
+		var err error
+		for _, pair := range test.pairs {
+			if err = values.Set(pair); err != nil {
+				break
+			}
+		}
+
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.pairs)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%q: unexpected error %s", test.pairs, err)
+		} else if !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("%q: got %v instead of %v", test.pairs, values, test.expected)
+		} else if str := values.String(); str != test.str {
+			t.Errorf("%q: formatted as %q instead of %q", test.pairs, str, test.str)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kv implements the binary encoding of the objects and the index keys for the key-value stores, which don't
+// provide any on their own. Objects are stored under the big-endian ID, which keeps them ordered by ID; index keys
+// consist of the order-preserving encoding of the value followed by the ID of the object.
+// The reads, queries & writes are implemented on top of the Reader & Writer interfaces, so that each store only
+// implements the access to its key space and its transactions.
+package kv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"math"
+)
+
+// IdKey encodes the ID so that the byte order of the keys is the same as the numeric order
+func IdKey(id uint64) []byte {
+	var key = make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// IdFromKey decodes the ID encoded by IdKey
+func IdFromKey(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key)
+}
+
+// Int32Key encodes the value so that the byte order of the keys is the same as the numeric order
+func Int32Key(value int32) []byte {
+	var key = make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(value)^(1<<31))
+	return key
+}
+
+// Int64Key encodes the value so that the byte order of the keys is the same as the numeric order
+func Int64Key(value int64) []byte {
+	var key = make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(value)^(1<<63))
+	return key
+}
+
+// Int64FromKey decodes the value encoded by Int64Key
+func Int64FromKey(key []byte) int64 {
+	return int64(binary.BigEndian.Uint64(key) ^ (1 << 63))
+}
+
+// IndexKey appends the object ID to the encoded value so that objects with the same value have unique keys
+func IndexKey(value []byte, id uint64) []byte {
+	return append(append(make([]byte, 0, len(value)+8), value...), IdKey(id)...)
+}
+
+// SplitIndexKey returns the encoded value and the object ID of an index key
+func SplitIndexKey(key []byte) ([]byte, uint64) {
+	return key[:len(key)-8], IdFromKey(key[len(key)-8:])
+}
+
+// EncodeEntity writes all fields except for the ID, which is the key: fixed-size numbers first, then the
+// variable-length string and byte slice, each prefixed by its length
+func EncodeEntity(object *models.Entity) []byte {
+	var buffer = make([]byte, 4+8+8+2*binary.MaxVarintLen64+len(object.String)+len(object.Bytes))
+	binary.BigEndian.PutUint32(buffer[0:], uint32(object.Int32))
+	binary.BigEndian.PutUint64(buffer[4:], uint64(object.Int64))
+	binary.BigEndian.PutUint64(buffer[12:], math.Float64bits(object.Float64))
+	var offset = 20
+	offset += binary.PutUvarint(buffer[offset:], uint64(len(object.String)))
+	offset += copy(buffer[offset:], object.String)
+	offset += binary.PutUvarint(buffer[offset:], uint64(len(object.Bytes)))
+	offset += copy(buffer[offset:], object.Bytes)
+	return buffer[:offset]
+}
+
+// DecodeEntity reads the object stored under the given ID key. The data is copied as the stores only guarantee the
+// key & value slices to be valid during the transaction.
+func DecodeEntity(key, value []byte) (*models.Entity, error) {
+	if len(key) != 8 || len(value) < 20 {
+		return nil, fmt.Errorf("invalid object data")
+	}
+
+	var object = &models.Entity{
+		Id:      IdFromKey(key),
+		Int32:   int32(binary.BigEndian.Uint32(value[0:])),
+		Int64:   int64(binary.BigEndian.Uint64(value[4:])),
+		Float64: math.Float64frombits(binary.BigEndian.Uint64(value[12:])),
+	}
+
+	var rest = value[20:]
+	var readSlice = func() ([]byte, error) {
+		length, n := binary.Uvarint(rest)
+		if n <= 0 || uint64(len(rest)-n) < length {
+			return nil, fmt.Errorf("invalid object %d data", object.Id)
+		}
+		var slice = rest[n : n+int(length)]
+		rest = rest[n+int(length):]
+		return slice, nil
+	}
+
+	if str, err := readSlice(); err != nil {
+		return nil, err
+	} else {
+		object.String = string(str)
+	}
+
+	if data, err := readSlice(); err != nil {
+		return nil, err
+	} else if len(data) > 0 {
+		object.Bytes = append([]byte(nil), data...)
+	}
+
+	return object, nil
+}
+
+// EncodeCustomer writes the customer without its orders, which are stored separately, linked by Order.CustomerId
+func EncodeCustomer(customer *models.Customer) []byte {
+	return []byte(customer.Name)
+}
+
+// DecodeCustomer reads the customer stored under the given ID key, without its orders
+func DecodeCustomer(key, value []byte) *models.Customer {
+	return &models.Customer{
+		Id:   IdFromKey(key),
+		Name: string(value),
+	}
+}
+
+// EncodeOrder writes all fields except for the ID, which is the key
+func EncodeOrder(order *models.Order) []byte {
+	var buffer = make([]byte, 24)
+	binary.BigEndian.PutUint64(buffer[0:], order.CustomerId)
+	binary.BigEndian.PutUint64(buffer[8:], uint64(order.Date))
+	binary.BigEndian.PutUint64(buffer[16:], math.Float64bits(order.Amount))
+	return buffer
+}
+
+// DecodeOrder reads the order stored under the given ID key
+func DecodeOrder(key, value []byte) (*models.Order, error) {
+	if len(key) != 8 || len(value) != 24 {
+		return nil, fmt.Errorf("invalid order data")
+	}
+
+	return &models.Order{
+		Id:         IdFromKey(key),
+		CustomerId: binary.BigEndian.Uint64(value[0:]),
+		Date:       int64(binary.BigEndian.Uint64(value[8:])),
+		Amount:     math.Float64frombits(binary.BigEndian.Uint64(value[16:])),
+	}, nil
+}
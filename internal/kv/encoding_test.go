@@ -0,0 +1,147 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"bytes"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEntityRoundTrip(t *testing.T) {
+	var tests = []*models.Entity{
+		{Id: 1},
+		{Id: 2, Int32: -1, Int64: -1, String: "Entity no. 2", Float64: -0.5},
+		{Id: math.MaxUint64, Int32: math.MaxInt32, Int64: math.MinInt64, String: "ž", Float64: math.Inf(1),
+			Bytes: []byte{0, 1, 2}},
+		{Id: 3, String: string(make([]byte, 1000)), Bytes: make([]byte, 100000)},
+	}
+
+	for _, object := range tests {
+		decoded, err := DecodeEntity(IdKey(object.Id), EncodeEntity(object))
+		if err != nil {
+			t.Errorf("%d: unexpected error %s", object.Id, err)
+		} else if !reflect.DeepEqual(decoded, object) {
+			t.Errorf("%d: decoded %+v instead of %+v", object.Id, decoded, object)
+		}
+	}
+}
+
+func TestEntityDecodeCopies(t *testing.T) {
+	var object = &models.Entity{Id: 1, String: "text", Bytes: []byte{1, 2, 3}}
+	var data = EncodeEntity(object)
+
+	decoded, err := DecodeEntity(IdKey(object.Id), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the stores reuse the buffers after the transaction
+	for i := range data {
+		data[i] = 0xff
+	}
+	if !reflect.DeepEqual(decoded, object) {
+		t.Errorf("decoded object changed with the data: %+v instead of %+v", decoded, object)
+	}
+}
+
+func TestEntityDecodeInvalid(t *testing.T) {
+	var data = EncodeEntity(&models.Entity{Id: 1, String: "text", Bytes: []byte{1, 2, 3}})
+
+	var tests = []struct {
+		name  string
+		key   []byte
+		value []byte
+	}{
+		{"short key", []byte{1}, data},
+		{"empty value", IdKey(1), nil},
+		{"truncated fields", IdKey(1), data[:19]},
+		{"truncated string", IdKey(1), data[:22]},
+		{"truncated bytes", IdKey(1), data[:len(data)-1]},
+	}
+
+	for _, test := range tests {
+		if _, err := DecodeEntity(test.key, test.value); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestRelationsRoundTrip(t *testing.T) {
+	var customer = &models.Customer{Id: 7, Name: "Customer no. 7"}
+	if decoded := DecodeCustomer(IdKey(customer.Id), EncodeCustomer(customer)); !reflect.DeepEqual(decoded, customer) {
+		t.Errorf("decoded %+v instead of %+v", decoded, customer)
+	}
+
+	var order = &models.Order{Id: 8, CustomerId: 7, Date: -1, Amount: 12.5}
+	if decoded, err := DecodeOrder(IdKey(order.Id), EncodeOrder(order)); err != nil {
+		t.Errorf("unexpected error %s", err)
+	} else if !reflect.DeepEqual(decoded, order) {
+		t.Errorf("decoded %+v instead of %+v", decoded, order)
+	}
+
+	if _, err := DecodeOrder(IdKey(order.Id), EncodeOrder(order)[1:]); err == nil {
+		t.Errorf("expected an error for a truncated order")
+	}
+}
+
+// TestKeyOrder checks that the byte order of the keys is the same as the order of the encoded values
+func TestKeyOrder(t *testing.T) {
+	var tests = []struct {
+		name string
+		keys [][]byte // in ascending order of the values
+	}{
+		{"id", [][]byte{IdKey(0), IdKey(1), IdKey(255), IdKey(256), IdKey(math.MaxUint64)}},
+		{"int32", [][]byte{Int32Key(math.MinInt32), Int32Key(-256), Int32Key(-1), Int32Key(0), Int32Key(1),
+			Int32Key(256), Int32Key(math.MaxInt32)}},
+		{"int64", [][]byte{Int64Key(math.MinInt64), Int64Key(-256), Int64Key(-1), Int64Key(0), Int64Key(1),
+			Int64Key(256), Int64Key(math.MaxInt64)}},
+		{"index by value, then id", [][]byte{IndexKey(Int32Key(-1), 2), IndexKey(Int32Key(0), 1),
+			IndexKey(Int32Key(0), 2), IndexKey(Int32Key(0), 256), IndexKey(Int32Key(1), 1)}},
+		{"string index", [][]byte{IndexKey([]byte("a"), 2), IndexKey([]byte("a"), 3), IndexKey([]byte("ab"), 1),
+			IndexKey([]byte("b"), 1)}},
+	}
+
+	for _, test := range tests {
+		for i := 1; i < len(test.keys); i++ {
+			if bytes.Compare(test.keys[i-1], test.keys[i]) >= 0 {
+				t.Errorf("%s: key %d (%x) isn't before key %d (%x)", test.name, i-1, test.keys[i-1], i, test.keys[i])
+			}
+		}
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	for _, id := range []uint64{0, 1, math.MaxUint64} {
+		if decoded := IdFromKey(IdKey(id)); decoded != id {
+			t.Errorf("id %d decoded as %d", id, decoded)
+		}
+	}
+
+	for _, value := range []int64{math.MinInt64, -1, 0, 1, math.MaxInt64} {
+		if decoded := Int64FromKey(Int64Key(value)); decoded != value {
+			t.Errorf("int64 %d decoded as %d", value, decoded)
+		}
+	}
+
+	var value, id = SplitIndexKey(IndexKey([]byte("text"), 42))
+	if string(value) != "text" || id != 42 {
+		t.Errorf("index key split into %q and %d", value, id)
+	}
+}
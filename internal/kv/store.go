@@ -0,0 +1,598 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Tables holds the names of the tables of an entity model: the objects, stored under their ID key, and an index for
+// each indexed property. Each store maps a table to its own key space, e.g. a bucket or a key prefix.
+type Tables struct {
+	Data        []byte
+	Int32Index  []byte // nil if the model isn't indexed
+	Int64Index  []byte
+	StringIndex []byte
+}
+
+func (tables *Tables) Indexed() bool {
+	return tables.Int32Index != nil
+}
+
+func (tables *Tables) All() [][]byte {
+	if tables.Indexed() {
+		return [][]byte{tables.Data, tables.Int32Index, tables.Int64Index, tables.StringIndex}
+	}
+	return [][]byte{tables.Data}
+}
+
+var PlainTables = &Tables{
+	Data: []byte("Entity"),
+}
+
+var IndexedTables = &Tables{
+	Data:        []byte("IndexedEntity"),
+	Int32Index:  []byte("IndexedEntity.Int32"),
+	Int64Index:  []byte("IndexedEntity.Int64"),
+	StringIndex: []byte("IndexedEntity.String"),
+}
+
+// relation tables, orders are linked to the customer using the index on the foreign key
+var (
+	CustomerTable        = []byte("Customer")
+	CustomerNameIndex    = []byte("Customer.Name")
+	OrderTable           = []byte("Order")
+	OrderCustomerIdIndex = []byte("Order.CustomerId")
+	RelationTables       = [][]byte{CustomerTable, CustomerNameIndex, OrderTable, OrderCustomerIdIndex}
+)
+
+// KeyPrefix returns the prefix of the keys of the table, for the stores with a single key space
+func KeyPrefix(table []byte) []byte {
+	return append(append(make([]byte, 0, len(table)+1), table...), '/')
+}
+
+// PrefixedKey prepends the key prefix of the table to the key, see KeyPrefix()
+func PrefixedKey(table, key []byte) []byte {
+	return append(append(make([]byte, 0, len(table)+1+len(key)), KeyPrefix(table)...), key...)
+}
+
+// Reader reads a consistent state of the database, e.g. in a read transaction or from a snapshot.
+// Keys are passed without the table; keys & values are only valid until the function they're passed to returns.
+type Reader interface {
+	Get(table, key []byte) ([]byte, error) // returns nil if the key doesn't exist
+
+	// Iterate calls fn for each key of the table in ascending order, starting at the given key (or the first one if
+	// it's nil), until it returns false. With reverse, the keys are visited in descending order from the last one.
+	Iterate(table, start []byte, reverse bool, fn func(key, value []byte) (bool, error)) error
+
+	// IterateKeys is the same as Iterate but doesn't read the values
+	IterateKeys(table, start []byte, reverse bool, fn func(key []byte) (bool, error)) error
+}
+
+// Writer writes in a read-write transaction or a batch; it reads the state the writes are based on
+type Writer interface {
+	Reader
+	Set(table, key, value []byte) error
+	Delete(table, key []byte) error
+	NextId(table []byte) (uint64, error) // returns a new ID for an object of the table
+}
+
+// Store implements SetModel() and the reading methods of perf.Executable on top of a Reader.
+// The executables embed it, implementing the writes and the access to their key space.
+type Store struct {
+	View   func(fn func(reader Reader) error) error // calls fn with a reader of the current state of the database
+	Tables *Tables                                  // tables of the current model
+}
+
+func (store *Store) SetModel(model string) error {
+	switch model {
+	case perf.ModelPlain:
+		store.Tables = PlainTables
+	case perf.ModelIndexed:
+		store.Tables = IndexedTables
+	default:
+		return fmt.Errorf("unknown model %s", model)
+	}
+	return nil
+}
+
+// scan calls fn for each object, ordered by ID, starting after the given ID, until it returns false
+func (store *Store) scan(reader Reader, afterId uint64, fn func(object *models.Entity) bool) error {
+	if afterId == math.MaxUint64 {
+		return nil
+	}
+
+	return reader.Iterate(store.Tables.Data, IdKey(afterId+1), false, func(key, value []byte) (bool, error) {
+		if object, err := DecodeEntity(key, value); err != nil {
+			return false, err
+		} else {
+			return fn(object), nil
+		}
+	})
+}
+
+// filter returns all objects matching the condition, scanning the whole table
+func (store *Store) filter(condition func(object *models.Entity) bool) ([]*models.Entity, error) {
+	var result []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		return store.scan(reader, 0, func(object *models.Entity) bool {
+			if condition(object) {
+				result = append(result, object)
+			}
+			return true
+		})
+	})
+	return result, err
+}
+
+// get reads the object with the given ID, returning nil if it doesn't exist
+func (store *Store) get(reader Reader, id uint64) (*models.Entity, error) {
+	var key = IdKey(id)
+	if value, err := reader.Get(store.Tables.Data, key); err != nil || value == nil {
+		return nil, err
+	} else {
+		return DecodeEntity(key, value)
+	}
+}
+
+// indexRange calls fn for the ID of each index entry with the value starting with the given prefix, until it returns
+// false; the value is passed as encoded in the key
+func indexRange(reader Reader, index, prefix []byte, fn func(value []byte, id uint64) (bool, error)) error {
+	return reader.IterateKeys(index, prefix, false, func(key []byte) (bool, error) {
+		// the keys are sorted so all the ones starting with the prefix follow each other; the first one that doesn't
+		// ends the range
+		var value, id = SplitIndexKey(key)
+		if !bytes.HasPrefix(value, prefix) {
+			return false, nil
+		}
+		return fn(value, id)
+	})
+}
+
+// indexInt64Greater calls fn for the ID of each Int64 index entry with the value greater than the given one
+func (store *Store) indexInt64Greater(reader Reader, value int64, fn func(value int64, id uint64) error) error {
+	if value == math.MaxInt64 {
+		return nil
+	}
+
+	return reader.IterateKeys(store.Tables.Int64Index, Int64Key(value+1), false, func(key []byte) (bool, error) {
+		var encoded, id = SplitIndexKey(key)
+		return true, fn(Int64FromKey(encoded), id)
+	})
+}
+
+// lookup reads the objects with the given IDs
+func (store *Store) lookup(reader Reader, ids []uint64) ([]*models.Entity, error) {
+	var items = make([]*models.Entity, 0, len(ids))
+	for _, id := range ids {
+		if object, err := store.get(reader, id); err != nil {
+			return nil, err
+		} else if object == nil {
+			return nil, fmt.Errorf("object %d referenced by the index doesn't exist", id)
+		} else {
+			items = append(items, object)
+		}
+	}
+	return items, nil
+}
+
+// queryIndex returns the objects of the index entries with the value starting with the given prefix, matching the
+// additional condition
+func (store *Store) queryIndex(index, prefix []byte, condition func(object *models.Entity) bool) ([]*models.Entity,
+	error) {
+	var items []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		var ids []uint64
+		if err := indexRange(reader, index, prefix, func(_ []byte, id uint64) (bool, error) {
+			ids = append(ids, id)
+			return true, nil
+		}); err != nil {
+			return err
+		}
+
+		found, err := store.lookup(reader, ids)
+		for _, object := range found {
+			if condition(object) {
+				items = append(items, object)
+			}
+		}
+		return err
+	})
+	return items, err
+}
+
+func (store *Store) ReadAll() ([]*models.Entity, error) {
+	return store.filter(func(object *models.Entity) bool {
+		return true
+	})
+}
+
+func (store *Store) ReadChunk(afterId uint64, limit int) ([]*models.Entity, error) {
+	var items []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		return store.scan(reader, afterId, func(object *models.Entity) bool {
+			items = append(items, object)
+			return len(items) < limit
+		})
+	})
+	return items, err
+}
+
+func (store *Store) QueryIdBetween(min, max uint64) ([]*models.Entity, error) {
+	if min == 0 {
+		min = 1
+	}
+
+	var items []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		return store.scan(reader, min-1, func(object *models.Entity) bool {
+			if object.Id > max {
+				return false
+			}
+			items = append(items, object)
+			return true
+		})
+	})
+	return items, err
+}
+
+func (store *Store) GetMany(ids []uint64) ([]*models.Entity, error) {
+	var items = make([]*models.Entity, 0, len(ids))
+	var err = store.View(func(reader Reader) error {
+		for _, id := range ids {
+			if object, err := store.get(reader, id); err != nil {
+				return err
+			} else if object != nil {
+				items = append(items, object)
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (store *Store) QueryStringPrefix(prefix string) ([]*models.Entity, error) {
+	if !store.Tables.Indexed() {
+		return store.filter(func(object *models.Entity) bool {
+			return strings.HasPrefix(object.String, prefix)
+		})
+	}
+
+	return store.queryIndex(store.Tables.StringIndex, []byte(prefix), func(object *models.Entity) bool {
+		return true
+	})
+}
+
+func (store *Store) QueryStringPrefixCaseInsensitive(prefix string) ([]*models.Entity, error) {
+	var lowerPrefix = strings.ToLower(prefix)
+	return store.filter(func(object *models.Entity) bool {
+		return strings.HasPrefix(strings.ToLower(object.String), lowerPrefix)
+	})
+}
+
+func (store *Store) QueryStringContains(text string) ([]*models.Entity, error) {
+	return store.filter(func(object *models.Entity) bool {
+		return strings.Contains(object.String, text)
+	})
+}
+
+func (store *Store) QueryInt32Equal(value int32) ([]*models.Entity, error) {
+	var condition = func(object *models.Entity) bool {
+		return object.Int32 == value
+	}
+
+	if store.Tables.Indexed() {
+		return store.queryIndex(store.Tables.Int32Index, Int32Key(value), condition)
+	}
+	return store.filter(condition)
+}
+
+func (store *Store) QueryInt64Greater(value int64) ([]*models.Entity, error) {
+	if !store.Tables.Indexed() {
+		return store.filter(func(object *models.Entity) bool {
+			return object.Int64 > value
+		})
+	}
+
+	var items []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		var ids []uint64
+		if err := store.indexInt64Greater(reader, value, func(_ int64, id uint64) error {
+			ids = append(ids, id)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		items, err = store.lookup(reader, ids)
+		return err
+	})
+	return items, err
+}
+
+func (store *Store) QueryFloat64Between(min, max float64) ([]*models.Entity, error) {
+	return store.filter(func(object *models.Entity) bool {
+		return object.Float64 >= min && object.Float64 <= max
+	})
+}
+
+func (store *Store) QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64) ([]*models.Entity,
+	error) {
+	var condition = func(object *models.Entity) bool {
+		return object.Int32 == int32Value && object.Int64 > int64Value
+	}
+
+	if store.Tables.Indexed() {
+		return store.queryIndex(store.Tables.Int32Index, Int32Key(int32Value), condition)
+	}
+	return store.filter(condition)
+}
+
+func (store *Store) QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64) ([]*models.Entity,
+	error) {
+	return store.filter(func(object *models.Entity) bool {
+		return object.Int32 == int32Value || (object.Float64 >= min && object.Float64 <= max)
+	})
+}
+
+func (store *Store) QueryInt64Ordered(descending bool, offset, limit int) ([]*models.Entity, error) {
+	if !store.Tables.Indexed() {
+		// without the index, all objects are read and sorted
+		items, err := store.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(items, func(i, j int) bool {
+			var a, b = items[i], items[j]
+			if descending {
+				a, b = b, a
+			}
+			return a.Int64 < b.Int64 || (a.Int64 == b.Int64 && a.Id < b.Id)
+		})
+
+		if offset >= len(items) {
+			return nil, nil
+		} else if offset+limit < len(items) {
+			return items[offset : offset+limit], nil
+		}
+		return items[offset:], nil
+	}
+
+	// the index keys are ordered by Int64, then by ID; descending is the reverse order of both
+	var items []*models.Entity
+	var err = store.View(func(reader Reader) error {
+		var ids []uint64
+		var position = 0
+		if err := reader.IterateKeys(store.Tables.Int64Index, nil, descending, func(key []byte) (bool, error) {
+			if position >= offset {
+				var _, id = SplitIndexKey(key)
+				ids = append(ids, id)
+			}
+			position++
+			return len(ids) < limit, nil
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		items, err = store.lookup(reader, ids)
+		return err
+	})
+	return items, err
+}
+
+func (store *Store) Get(id uint64) (*models.Entity, error) {
+	var object *models.Entity
+	var err = store.View(func(reader Reader) error {
+		var err error
+		object, err = store.get(reader, id)
+		return err
+	})
+	return object, err
+}
+
+func (store *Store) Count() (uint64, error) {
+	var count uint64
+	var err = store.View(func(reader Reader) error {
+		return reader.IterateKeys(store.Tables.Data, nil, false, func([]byte) (bool, error) {
+			count++
+			return true, nil
+		})
+	})
+	return count, err
+}
+
+func (store *Store) CountInt64Greater(value int64) (uint64, error) {
+	if !store.Tables.Indexed() {
+		items, err := store.QueryInt64Greater(value)
+		return uint64(len(items)), err
+	}
+
+	// the index keys contain the values, the objects don't need to be read
+	var count uint64
+	var err = store.View(func(reader Reader) error {
+		return store.indexInt64Greater(reader, value, func(int64, uint64) error {
+			count++
+			return nil
+		})
+	})
+	return count, err
+}
+
+// addInt64 adds the value to the aggregates, the average is computed by the caller when all values are added
+func addInt64(result *perf.Int64Aggregates, value int64) {
+	if result.Count == 0 || value < result.Min {
+		result.Min = value
+	}
+	if result.Count == 0 || value > result.Max {
+		result.Max = value
+	}
+	result.Count++
+	result.Sum += value
+}
+
+// addFloat64 adds the value to the aggregates, the average is computed by the caller when all values are added
+func addFloat64(result *perf.Float64Aggregates, value float64) {
+	if result.Count == 0 || value < result.Min {
+		result.Min = value
+	}
+	if result.Count == 0 || value > result.Max {
+		result.Max = value
+	}
+	result.Count++
+	result.Sum += value
+}
+
+// aggregateInt64 computes the aggregates of the Int64 values of the objects matching the condition
+func (store *Store) aggregateInt64(condition func(object *models.Entity) bool) (perf.Int64Aggregates, error) {
+	var result perf.Int64Aggregates
+	var err = store.View(func(reader Reader) error {
+		return store.scan(reader, 0, func(object *models.Entity) bool {
+			if condition(object) {
+				addInt64(&result, object.Int64)
+			}
+			return true
+		})
+	})
+
+	if result.Count > 0 {
+		result.Average = float64(result.Sum) / float64(result.Count)
+	}
+	return result, err
+}
+
+// aggregateFloat64 computes the aggregates of the Float64 values of the objects matching the condition
+func (store *Store) aggregateFloat64(condition func(object *models.Entity) bool) (perf.Float64Aggregates, error) {
+	var result perf.Float64Aggregates
+	var err = store.View(func(reader Reader) error {
+		return store.scan(reader, 0, func(object *models.Entity) bool {
+			if condition(object) {
+				addFloat64(&result, object.Float64)
+			}
+			return true
+		})
+	})
+
+	if result.Count > 0 {
+		result.Average = result.Sum / float64(result.Count)
+	}
+	return result, err
+}
+
+func (store *Store) AggregateInt64() (perf.Int64Aggregates, error) {
+	return store.aggregateInt64(func(object *models.Entity) bool {
+		return true
+	})
+}
+
+func (store *Store) AggregateInt64Greater(value int64) (perf.Int64Aggregates, error) {
+	if !store.Tables.Indexed() {
+		return store.aggregateInt64(func(object *models.Entity) bool {
+			return object.Int64 > value
+		})
+	}
+
+	// the index keys contain the values, the objects don't need to be read
+	var result perf.Int64Aggregates
+	var err = store.View(func(reader Reader) error {
+		return store.indexInt64Greater(reader, value, func(value int64, _ uint64) error {
+			addInt64(&result, value)
+			return nil
+		})
+	})
+
+	if result.Count > 0 {
+		result.Average = float64(result.Sum) / float64(result.Count)
+	}
+	return result, err
+}
+
+func (store *Store) AggregateFloat64() (perf.Float64Aggregates, error) {
+	return store.aggregateFloat64(func(object *models.Entity) bool {
+		return true
+	})
+}
+
+func (store *Store) AggregateFloat64Between(min, max float64) (perf.Float64Aggregates, error) {
+	return store.aggregateFloat64(func(object *models.Entity) bool {
+		return object.Float64 >= min && object.Float64 <= max
+	})
+}
+
+// relations
+
+// ordersOf reads the orders of the given customer using the index on the foreign key
+func ordersOf(reader Reader, customerId uint64) ([]*models.Order, error) {
+	var orders []*models.Order
+	var err = indexRange(reader, OrderCustomerIdIndex, IdKey(customerId), func(_ []byte, id uint64) (bool, error) {
+		var key = IdKey(id)
+		if value, err := reader.Get(OrderTable, key); err != nil {
+			return false, err
+		} else if order, err := DecodeOrder(key, value); err != nil {
+			return false, err
+		} else {
+			orders = append(orders, order)
+		}
+		return true, nil
+	})
+	return orders, err
+}
+
+func (store *Store) ReadCustomers() ([]*models.Customer, error) {
+	var customers []*models.Customer
+	var err = store.View(func(reader Reader) error {
+		return reader.Iterate(CustomerTable, nil, false, func(key, value []byte) (bool, error) {
+			var customer = DecodeCustomer(key, value)
+
+			var err error
+			if customer.Orders, err = ordersOf(reader, customer.Id); err != nil {
+				return false, err
+			}
+			customers = append(customers, customer)
+			return true, nil
+		})
+	})
+	return customers, err
+}
+
+func (store *Store) QueryOrdersByCustomerName(name string) ([]*models.Order, error) {
+	var result []*models.Order
+	var err = store.View(func(reader Reader) error {
+		return indexRange(reader, CustomerNameIndex, []byte(name), func(value []byte, customerId uint64) (bool, error) {
+			// the index range includes names starting with the given one
+			if string(value) != name {
+				return true, nil
+			}
+
+			if orders, err := ordersOf(reader, customerId); err != nil {
+				return false, err
+			} else {
+				result = append(result, orders...)
+			}
+			return true, nil
+		})
+	})
+	return result, err
+}
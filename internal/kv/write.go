@@ -0,0 +1,124 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kv
+
+import "github.com/objectbox/objectbox-go-performance/internal/models"
+
+// Put inserts (assigning a new ID) or updates the object, including its index entries
+func Put(writer Writer, tables *Tables, object *models.Entity) error {
+	if object.Id == 0 {
+		if id, err := writer.NextId(tables.Data); err != nil {
+			return err
+		} else {
+			object.Id = id
+		}
+	} else {
+		// the index entries of the previous values must be removed
+		if err := removeIndexes(writer, tables, object.Id); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Set(tables.Data, IdKey(object.Id), EncodeEntity(object)); err != nil {
+		return err
+	}
+
+	if tables.Indexed() {
+		if err := writer.Set(tables.Int32Index, IndexKey(Int32Key(object.Int32), object.Id), nil); err != nil {
+			return err
+		}
+		if err := writer.Set(tables.Int64Index, IndexKey(Int64Key(object.Int64), object.Id), nil); err != nil {
+			return err
+		}
+		if err := writer.Set(tables.StringIndex, IndexKey([]byte(object.String), object.Id), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeIndexes removes the index entries of the stored object with the given ID, if it exists
+func removeIndexes(writer Writer, tables *Tables, id uint64) error {
+	if !tables.Indexed() {
+		return nil
+	}
+
+	var key = IdKey(id)
+	value, err := writer.Get(tables.Data, key)
+	if err != nil || value == nil {
+		return err
+	}
+
+	old, err := DecodeEntity(key, value)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Delete(tables.Int32Index, IndexKey(Int32Key(old.Int32), id)); err != nil {
+		return err
+	}
+	if err := writer.Delete(tables.Int64Index, IndexKey(Int64Key(old.Int64), id)); err != nil {
+		return err
+	}
+	return writer.Delete(tables.StringIndex, IndexKey([]byte(old.String), id))
+}
+
+// Remove removes the object with the given ID, including its index entries
+func Remove(writer Writer, tables *Tables, id uint64) error {
+	if err := removeIndexes(writer, tables, id); err != nil {
+		return err
+	}
+	return writer.Delete(tables.Data, IdKey(id))
+}
+
+// PutCustomers inserts the customers including their orders, assigning new IDs
+func PutCustomers(writer Writer, customers []*models.Customer) error {
+	for _, customer := range customers {
+		if customer.Id == 0 {
+			if id, err := writer.NextId(CustomerTable); err != nil {
+				return err
+			} else {
+				customer.Id = id
+			}
+		}
+
+		if err := writer.Set(CustomerTable, IdKey(customer.Id), EncodeCustomer(customer)); err != nil {
+			return err
+		} else if err := writer.Set(CustomerNameIndex, IndexKey([]byte(customer.Name), customer.Id), nil); err != nil {
+			return err
+		}
+
+		for _, order := range customer.Orders {
+			if order.Id == 0 {
+				if id, err := writer.NextId(OrderTable); err != nil {
+					return err
+				} else {
+					order.Id = id
+				}
+			}
+			order.CustomerId = customer.Id
+
+			if err := writer.Set(OrderTable, IdKey(order.Id), EncodeOrder(order)); err != nil {
+				return err
+			} else if err := writer.Set(OrderCustomerIdIndex, IndexKey(IdKey(customer.Id), order.Id),
+				nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
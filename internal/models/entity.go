@@ -22,4 +22,26 @@ type Entity struct {
 	Int64   int64
 	String  string
 	Float64 float64
+	Bytes   []byte
+}
+
+// IndexedEntity has the same fields as Entity but with indexes on the queried properties.
+// The field types must stay the same as Entity's, the executables convert the objects between the two types.
+type IndexedEntity struct {
+	Id      uint64 `gorm:"primary_key" storm:"id,increment"`
+	Int32   int32  `objectbox:"index" gorm:"index" storm:"index"`
+	Int64   int64  `objectbox:"index" gorm:"index" storm:"index"`
+	String  string `objectbox:"index:value" gorm:"index" storm:"index"`
+	Float64 float64
+	Bytes   []byte
+}
+
+// FromIndexed converts the slice without copying the objects.
+// Only used where the database fills a slice of the model's type, as it allocates a new slice.
+func FromIndexed(items []*IndexedEntity) []*Entity {
+	var result = make([]*Entity, len(items))
+	for i, item := range items {
+		result[i] = (*Entity)(item)
+	}
+	return result
 }
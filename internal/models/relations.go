@@ -0,0 +1,34 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+// Customer has a to-many relation to its orders, which is the reverse side of the Order.CustomerId to-one relation.
+// ObjectBox Go doesn't support back-links (and a standalone to-many relation would form a relation cycle) and Storm
+// stores the objects as JSON so both exclude the field and load the orders using Order.CustomerId instead.
+type Customer struct {
+	Id     uint64   `gorm:"primary_key" storm:"id,increment"`
+	Name   string   `objectbox:"index" gorm:"index" storm:"index"`
+	Orders []*Order `objectbox:"-" gorm:"foreignkey:CustomerId" json:"-"`
+}
+
+// Order has a to-one relation to its customer.
+type Order struct {
+	Id         uint64 `gorm:"primary_key" storm:"id,increment"`
+	CustomerId uint64 `objectbox:"link:Customer" gorm:"index" storm:"index"`
+	Date       int64
+	Amount     float64
+}
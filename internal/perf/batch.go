@@ -0,0 +1,99 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"log"
+	"runtime"
+)
+
+// putBatches inserts the objects in transactions of the given batch size, all at once if batchSize is 0
+func (perf *Executor) putBatches(items []*models.Entity, batchSize int) {
+	if batchSize <= 0 || batchSize >= len(items) {
+		assert(perf.exec.PutBulk(items))
+		return
+	}
+
+	for offset := 0; offset < len(items); offset += batchSize {
+		var end = offset + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		assert(perf.exec.PutBulk(items[offset:end]))
+	}
+}
+
+// runBatchSweep inserts all the objects with each of the configured batch sizes and prints the results
+func (perf *Executor) runBatchSweep(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var inserts = perf.PrepareData(gen, options.Count)
+	var objectSize = averageObjectSize(inserts)
+	var size uint64
+
+	for i := 0; i < options.Runs; i++ {
+		for _, batchSize := range options.BatchSweep {
+			removeIds(inserts)
+			perf.PutBatches(inserts, batchSize)
+
+			if size_, err := perf.exec.Size(); err != nil {
+				panic(err)
+			} else {
+				size = size_
+			}
+
+			perf.RemoveAll(len(inserts))
+		}
+
+		log.Printf("%d/%d batch size sweep finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions []string
+	for _, batchSize := range options.BatchSweep {
+		functions = append(functions, fmt.Sprintf(putBatchesFunction, batchSize))
+	}
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("Batch size sweep: model: %s, objects: %d, average object size: %d bytes", model, options.Count,
+			objectSize),
+		fmt.Sprintf("model=%s/count=%d/size=%d", model, options.Count, objectSize),
+		objectSize, size)
+}
+
+// putBatchesFunction is the name of the batch size sweep measurements, formatted with the batch size
+const putBatchesFunction = "PutBatches/batch=%d"
+
+// PutBatches inserts the objects in transactions of the given batch size, reporting the throughput
+func (perf *Executor) PutBatches(items []*models.Entity, batchSize int) {
+	var fun = fmt.Sprintf(putBatchesFunction, batchSize)
+	var start = perf.start(len(items))
+	perf.putBatches(items, batchSize)
+	perf.trackTimeAs(fun, start)
+
+	var samples = perf.samples[fun]
+	var last = samples[len(samples)-1]
+	perf.setMetric(fun, "objects/s", float64(last.objects)/last.duration.Seconds())
+}
@@ -0,0 +1,419 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"log"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// readerCounts returns the numbers of reader goroutines to run the test with: 1, 2, 4, ... up to max (inclusive)
+func readerCounts(max int) []int {
+	var result []int
+	for n := 1; n < max; n *= 2 {
+		result = append(result, n)
+	}
+	return append(result, max)
+}
+
+// concurrently runs the operation in the given number of goroutines, repeatedly, until the duration elapses.
+// Each goroutine gets its index and its own random generator. Returns the total number of executed operations
+// and the first error, which stops the goroutine that encountered it.
+func concurrently(goroutines int, duration time.Duration, seed int64,
+	operation func(goroutine int, rnd *rand.Rand) error) (int, error) {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var total = 0
+	var firstErr error
+
+	var deadline = time.Now().Add(duration)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(goroutine int, rnd *rand.Rand) {
+			defer wg.Done()
+
+			var count = 0
+			var err error
+			for err == nil && time.Now().Before(deadline) {
+				err = operation(goroutine, rnd)
+				count++
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			total += count
+			if firstErr == nil {
+				firstErr = err
+			}
+		}(i, rand.New(rand.NewSource(seed+int64(i))))
+	}
+	wg.Wait()
+
+	return total, firstErr
+}
+
+// runConcurrentReaders executes the concurrent readers test suite and prints the results
+func (perf *Executor) runConcurrentReaders(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var inserts = perf.PrepareData(gen, options.Count)
+	var counts = readerCounts(options.Readers)
+	var size uint64
+
+	// number of objects for each Int32 value, to check the query results
+	var int32Counts = map[int32]int{}
+	for _, object := range inserts {
+		int32Counts[object.Int32]++
+	}
+
+	for i := 0; i < options.Runs; i++ {
+		removeIds(inserts)
+		perf.PutBulk(inserts, options.BatchSize)
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		for _, readers := range counts {
+			perf.ConcurrentGet(inserts, readers, options.ConcurrentDuration, options.Seed)
+			perf.ConcurrentQuery(inserts, int32Counts, readers, options.ConcurrentDuration, options.Seed)
+		}
+		perf.trackScaling(concurrentGetFunction, counts)
+		perf.trackScaling(concurrentQueryFunction, counts)
+
+		perf.RemoveAll(len(inserts))
+
+		log.Printf("%d/%d concurrent readers finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions []string
+	for _, format := range []string{concurrentGetFunction, concurrentQueryFunction} {
+		for _, readers := range counts {
+			functions = append(functions, fmt.Sprintf(format, readers))
+		}
+	}
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("Concurrent readers: model: %s, objects: %d, duration: %v", model, options.Count,
+			options.ConcurrentDuration),
+		fmt.Sprintf("model=%s/count=%d", model, options.Count),
+		0, size)
+}
+
+// names of the concurrent readers measurements, formatted with the number of readers
+const (
+	concurrentGetFunction   = "ConcurrentGet/readers=%d"
+	concurrentQueryFunction = "ConcurrentQuery/readers=%d"
+)
+
+// ConcurrentGet reads random objects by ID from the given number of goroutines in parallel
+func (perf *Executor) ConcurrentGet(inserts []*models.Entity, readers int, duration time.Duration, seed int64) {
+	var start = perf.start(0)
+	operations, err := concurrently(readers, duration, seed, func(_ int, rnd *rand.Rand) error {
+		var expected = inserts[rnd.Intn(len(inserts))]
+		if item, err := perf.exec.Get(expected.Id); err != nil {
+			return err
+		} else if item == nil || item.Id != expected.Id {
+			return fmt.Errorf("object %d not found", expected.Id)
+		}
+		return nil
+	})
+	start.objects = operations
+	perf.trackTimeAs(fmt.Sprintf(concurrentGetFunction, readers), start)
+	assert(err)
+}
+
+// ConcurrentQuery executes QueryInt32Equal with random values from the given number of goroutines in parallel
+func (perf *Executor) ConcurrentQuery(inserts []*models.Entity, int32Counts map[int32]int, readers int,
+	duration time.Duration, seed int64) {
+	var start = perf.start(0)
+	operations, err := concurrently(readers, duration, seed, func(_ int, rnd *rand.Rand) error {
+		var value = inserts[rnd.Intn(len(inserts))].Int32
+		if items, err := perf.exec.QueryInt32Equal(value); err != nil {
+			return err
+		} else if len(items) != int32Counts[value] {
+			return fmt.Errorf("invalid number of objects returned by QueryInt32Equal(%d) - %d instead of %d",
+				value, len(items), int32Counts[value])
+		}
+		return nil
+	})
+	start.objects = operations
+	perf.trackTimeAs(fmt.Sprintf(concurrentQueryFunction, readers), start)
+	assert(err)
+}
+
+// trackScaling adds throughput & scaling efficiency metrics to the last samples of the function measured with the
+// given reader counts. Scaling efficiency is the throughput relative to N times the single-reader throughput.
+func (perf *Executor) trackScaling(format string, readerCounts []int) {
+	var baseline float64
+	for _, readers := range readerCounts {
+		var fun = fmt.Sprintf(format, readers)
+		var samples = perf.samples[fun]
+		var last = samples[len(samples)-1]
+
+		var throughput = float64(last.objects) / last.duration.Seconds()
+		if readers == 1 {
+			baseline = throughput
+		}
+
+		perf.setMetric(fun, "ops/s", throughput)
+		if baseline > 0 {
+			perf.setMetric(fun, "scaling-efficiency", throughput/baseline/float64(readers))
+		}
+	}
+}
+
+// operation types of the mixed workload
+const (
+	mixedGet = iota
+	mixedQuery
+	mixedInsert
+	mixedUpdate
+	mixedRemove
+)
+
+// mixedOperations are the names of the mixed workload operation types, indexed by the type
+var mixedOperations = []string{"get", "query", "insert", "update", "remove"}
+
+// mixedWorkloadFunction is the name of the mixed workload measurement
+const mixedWorkloadFunction = "MixedWorkload"
+
+// operationStats collects the results of operations of multiple types, executed by a single goroutine
+type operationStats struct {
+	latencies [][]time.Duration // latencies of successful operations, indexed by the operation type
+	errors    []int             // numbers of failed operations, indexed by the operation type
+	messages  map[string]int    // numbers of occurrences of each error message
+}
+
+func newOperationStats(types int) operationStats {
+	return operationStats{
+		latencies: make([][]time.Duration, types),
+		errors:    make([]int, types),
+		messages:  map[string]int{},
+	}
+}
+
+// record stores the latency of the operation which started at the given time or its error
+func (stats *operationStats) record(operation int, start time.Time, err error) {
+	var latency = time.Since(start)
+	if err != nil {
+		stats.errors[operation]++
+		stats.messages[err.Error()]++
+	} else {
+		stats.latencies[operation] = append(stats.latencies[operation], latency)
+	}
+}
+
+// trackOperations adds a sample for each executed operation type, named "<fun>/op=<name>", with the results collected
+// by all the given stats during the last measurement of the given function; adds throughput & error metrics to all
+func (perf *Executor) trackOperations(fun string, names []string, stats []*operationStats) {
+	var samples = perf.samples[fun]
+	var elapsed = samples[len(samples)-1].duration
+
+	var totalOperations, totalErrors = 0, 0
+	var messages = map[string]int{}
+	for operation, name := range names {
+		var latencies []time.Duration
+		var errors = 0
+		for _, s := range stats {
+			latencies = append(latencies, s.latencies[operation]...)
+			errors += s.errors[operation]
+		}
+		totalOperations += len(latencies)
+		totalErrors += errors
+
+		// operation types not included in the workload
+		if len(latencies) == 0 && errors == 0 {
+			continue
+		}
+
+		// the operations are reported as separate functions with the duration of the whole measurement
+		var opFun = fun + "/op=" + name
+		perf.samples[opFun] = append(perf.samples[opFun], sample{
+			duration:  elapsed,
+			objects:   len(latencies),
+			latencies: latencies,
+		})
+		perf.setMetric(opFun, "ops/s", float64(len(latencies))/elapsed.Seconds())
+		perf.setMetric(opFun, "errors", float64(errors))
+	}
+	perf.setMetric(fun, "ops/s", float64(totalOperations)/elapsed.Seconds())
+	perf.setMetric(fun, "errors", float64(totalErrors))
+
+	for _, s := range stats {
+		for message, count := range s.messages {
+			messages[message] += count
+		}
+	}
+	for message, count := range messages {
+		log.Printf("%s error occurred %d times: %s", fun, count, message)
+	}
+}
+
+// operationFunctions returns the names of the samples added by trackOperations(), including the given function
+func operationFunctions(fun string, names []string) []string {
+	var result = []string{fun}
+	for _, name := range names {
+		result = append(result, fun+"/op="+name)
+	}
+	return result
+}
+
+// mixedWorker holds the state of a single goroutine of the mixed workload
+type mixedWorker struct {
+	operationStats
+	gen *Generator
+	own []*models.Entity // objects inserted by this worker, the only ones it updates and removes
+}
+
+func newMixedWorker(options Options) *mixedWorker {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	return &mixedWorker{
+		operationStats: newOperationStats(len(mixedOperations)),
+		gen:            gen,
+	}
+}
+
+// execute runs a single randomly chosen operation, recording its latency or its error
+func (worker *mixedWorker) execute(exec Executable, inserts []*models.Entity, rnd *rand.Rand, readRatio float64) {
+	var operation int
+	if rnd.Float64() < readRatio {
+		operation = mixedGet + rnd.Intn(2)
+	} else if len(worker.own) == 0 {
+		operation = mixedInsert
+	} else {
+		operation = mixedInsert + rnd.Intn(3)
+	}
+
+	var start = time.Now()
+	var err error
+	switch operation {
+	case mixedGet:
+		// only the initial objects are read because they are never removed
+		var expected = inserts[rnd.Intn(len(inserts))]
+		var item *models.Entity
+		if item, err = exec.Get(expected.Id); err == nil && item == nil {
+			err = fmt.Errorf("object %d not found", expected.Id)
+		}
+
+	case mixedQuery:
+		_, err = exec.QueryInt32Equal(inserts[rnd.Intn(len(inserts))].Int32)
+
+	case mixedInsert:
+		var object = worker.gen.Entity()
+		if err = exec.Put(object); err == nil {
+			worker.own = append(worker.own, object)
+		}
+
+	case mixedUpdate:
+		var object = worker.own[rnd.Intn(len(worker.own))]
+		object.Int64++
+		err = exec.Put(object)
+
+	case mixedRemove:
+		var i = rnd.Intn(len(worker.own))
+		if err = exec.Remove(worker.own[i]); err == nil {
+			worker.own[i] = worker.own[len(worker.own)-1]
+			worker.own = worker.own[:len(worker.own)-1]
+		}
+	}
+
+	worker.record(operation, start, err)
+}
+
+// runMixed executes the mixed read/write workload test suite and prints the results
+func (perf *Executor) runMixed(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var inserts = perf.PrepareData(gen, options.Count)
+	var size uint64
+
+	for i := 0; i < options.Runs; i++ {
+		removeIds(inserts)
+		perf.PutBulk(inserts, options.BatchSize)
+
+		// each worker generates different objects to insert
+		var workers = make([]*mixedWorker, options.MixedWorkers)
+		for w := range workers {
+			var workerOptions = options
+			workerOptions.Seed = options.Seed + int64(w) + 1
+			workers[w] = newMixedWorker(workerOptions)
+		}
+
+		perf.MixedWorkload(inserts, workers, options.ReadRatio, options.ConcurrentDuration, options.Seed)
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		perf.RemoveAll(len(inserts))
+
+		log.Printf("%d/%d mixed workload finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	perf.printResults(options, operationFunctions(mixedWorkloadFunction, mixedOperations),
+		fmt.Sprintf("Mixed workload: model: %s, objects: %d, goroutines: %d, read ratio: %v, duration: %v", model,
+			options.Count, options.MixedWorkers, options.ReadRatio, options.ConcurrentDuration),
+		fmt.Sprintf("model=%s/count=%d/goroutines=%d/reads=%v", model, options.Count, options.MixedWorkers,
+			options.ReadRatio),
+		0, size)
+}
+
+// MixedWorkload runs the workers in parallel, each executing random reads and writes; failed operations, e.g. due
+// to the database being locked by another writer, are counted and reported separately for each operation type
+func (perf *Executor) MixedWorkload(inserts []*models.Entity, workers []*mixedWorker, readRatio float64,
+	duration time.Duration, seed int64) {
+	var start = perf.start(0)
+	operations, err := concurrently(len(workers), duration, seed, func(goroutine int, rnd *rand.Rand) error {
+		workers[goroutine].execute(perf.exec, inserts, rnd, readRatio)
+		return nil
+	})
+	start.objects = operations
+	perf.trackTimeAs(mixedWorkloadFunction, start)
+	assert(err)
+
+	var stats = make([]*operationStats, len(workers))
+	for i, worker := range workers {
+		stats[i] = &worker.operationStats
+	}
+	perf.trackOperations(mixedWorkloadFunction, mixedOperations, stats)
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The crash consistency test runs the writes in a child process, started as the same executable with -crash-child,
+// which is killed at a random point. The parent then opens the database and verifies its contents.
+// The child reports each committed transaction on its standard output, see crashReady and crashCommitted.
+
+// messages of the child process protocol
+const (
+	crashReady     = "crash-child: ready"
+	crashCommitted = "crash-child: committed "
+)
+
+// crashTransactionSize is the number of objects written in a single transaction, unless configured by -batch-size
+const crashTransactionSize = 100
+
+// crashTransactions returns the sizes of the transactions written by the child process
+func crashTransactions(options Options) []int {
+	var size = options.BatchSize
+	if size <= 0 {
+		size = crashTransactionSize
+	}
+
+	var sizes []int
+	for written := 0; written < options.Count; written += size {
+		if written+size > options.Count {
+			sizes = append(sizes, options.Count-written)
+		} else {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
+
+// runCrashChild writes the objects in transactions, reporting each committed one, until killed by the parent process.
+// If all objects are written before that, the process exits without removing the database.
+func (perf *Executor) runCrashChild(options Options) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	fmt.Println(crashReady)
+
+	for i, size := range crashTransactions(options) {
+		assert(perf.exec.PutBulk(gen.Entities(size)))
+		fmt.Println(crashCommitted + strconv.Itoa(i+1))
+	}
+
+	assert(perf.exec.Release())
+	os.Exit(0)
+}
+
+// runCrash executes the crash consistency test and prints the results
+func (perf *Executor) runCrash(options Options, model string) {
+	var rnd = rand.New(rand.NewSource(options.Seed))
+	var transactions = crashTransactions(options)
+
+	var size uint64
+
+	// the child process creates the database from scratch, it must not be open in this process meanwhile
+	assert(perf.exec.Release())
+
+	for i := 0; i < options.CrashIterations; i++ {
+		var delay = time.Duration(rnd.Int63n(int64(options.CrashMaxDelay)))
+		var committed = perf.crashChild(model, delay)
+
+		var start = perf.start(0)
+		var err = perf.exec.Open()
+		perf.trackTimeAs("CrashRecovery", start)
+
+		if err == nil {
+			err = perf.verifyCrash(options, transactions, committed)
+			if size_, err := perf.exec.Size(); err == nil {
+				size = size_
+			}
+			assert(perf.exec.Release())
+		}
+
+		perf.setMetric("CrashRecovery", "committed-tx", float64(committed))
+		if err != nil {
+			log.Printf("%d/%d crash consistency check failed: %s", i+1, options.CrashIterations, err)
+			perf.setMetric("CrashRecovery", "failures", 1)
+		} else {
+			perf.setMetric("CrashRecovery", "failures", 0)
+		}
+
+		log.Printf("%d/%d crash consistency finished, killed after %v and %d committed transactions", i+1,
+			options.CrashIterations, delay, committed)
+	}
+
+	// the database must be open for the following tests, start with an empty one, whatever the child left behind
+	assert(perf.exec.Init())
+	assert(perf.exec.SetModel(model))
+
+	perf.printResults(options, []string{"CrashRecovery"},
+		fmt.Sprintf("Crash consistency: model: %s, objects: %d, transactions: %d, kills: %d", model, options.Count,
+			len(transactions), options.CrashIterations),
+		fmt.Sprintf("model=%s/count=%d/tx=%d", model, options.Count, len(transactions)),
+		0, size)
+}
+
+// crashChild starts the child process writing the objects, kills it after the given delay and returns the number of
+// transactions it reported as committed
+func (perf *Executor) crashChild(model string, delay time.Duration) int {
+	// the same arguments, the flags added later take precedence
+	var args = append(os.Args[1:], "-crash-child", "-models", model)
+	var child = exec.Command(os.Args[0], args...)
+	child.Stderr = os.Stderr
+
+	stdout, err := child.StdoutPipe()
+	assert(err)
+	assert(child.Start())
+
+	var ready = make(chan bool)
+	var done = make(chan int)
+	go func() {
+		var committed = 0
+		var scanner = bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var line = scanner.Text()
+			if line == crashReady {
+				close(ready)
+			} else if strings.HasPrefix(line, crashCommitted) {
+				var err error
+				committed, err = strconv.Atoi(strings.TrimPrefix(line, crashCommitted))
+				assert(err)
+			}
+		}
+		done <- committed
+	}()
+
+	// the random delay starts when the child's database is set up, crashes of the setup itself are not tested
+	select {
+	case <-ready:
+	case <-done:
+		panic(fmt.Errorf("crash consistency child process failed: %v", child.Wait()))
+	}
+
+	time.Sleep(delay)
+
+	// the child may have finished already, then it has exited by itself and killing it fails
+	_ = child.Process.Kill()
+	var committed = <-done
+	_ = child.Wait()
+	return committed
+}
+
+// verifyCrash checks that all the committed transactions are in the database and none of the uncommitted ones,
+// except for the one which may have been committed just before the kill but not reported anymore
+func (perf *Executor) verifyCrash(options Options, transactions []int, committed int) error {
+	items, err := perf.exec.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	var expectedMin, expectedMax = 0, 0
+	for i, size := range transactions {
+		if i < committed {
+			expectedMin += size
+			expectedMax += size
+		} else if i == committed {
+			expectedMax += size
+		}
+	}
+
+	if len(items) != expectedMin && len(items) != expectedMax {
+		return fmt.Errorf("%d objects found, expected %d committed objects (or %d including the transaction in "+
+			"progress)", len(items), expectedMin, expectedMax)
+	}
+
+	// IDs are assigned in ascending order, the objects must match the generated ones in the same order
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Id < items[j].Id
+	})
+
+	gen, err := NewGenerator(options)
+	if err != nil {
+		return err
+	}
+
+	for _, size := range transactions {
+		if len(items) == 0 {
+			break
+		}
+
+		for _, object := range gen.Entities(size) {
+			object.Id = items[0].Id
+			if !entityEquals(items[0], object) {
+				return fmt.Errorf("object %d differs from the one written: %+v instead of %+v", object.Id, items[0],
+					object)
+			}
+			items = items[1:]
+		}
+	}
+
+	return nil
+}
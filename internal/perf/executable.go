@@ -18,9 +18,20 @@ package perf
 
 import "github.com/objectbox/objectbox-go-performance/internal/models"
 
+// entity models the tests can be executed with
+const (
+	ModelPlain   = "plain"   // models.Entity
+	ModelIndexed = "indexed" // models.IndexedEntity
+)
+
+// Executable is implemented by each of the tested databases.
+// Reading methods (Get, GetMany, ReadAll, queries, count & aggregates) must be safe to call from multiple goroutines.
 type Executable interface {
 	Init() error
+	SetModel(model string) error
 	Close() error
+	Release() error // closes the database, keeping the data, e.g. to open it again or from another process
+	Open() error    // opens the database with the existing data, e.g. after Release()
 	Size() (uint64, error)
 	RemoveAll() error
 	RemoveBulk(items []*models.Entity) error
@@ -28,6 +39,59 @@ type Executable interface {
 	AwaitAsyncCompletion() error
 	PutBulk(items []*models.Entity) error
 	ReadAll() ([]*models.Entity, error)
+	ReadChunk(afterId uint64, limit int) ([]*models.Entity, error) // objects with a greater ID, ordered by ID
 	QueryIdBetween(min, max uint64) ([]*models.Entity, error)
+	GetMany(ids []uint64) ([]*models.Entity, error) // returns the existing objects in any order
 	QueryStringPrefix(prefix string) ([]*models.Entity, error)
+	QueryStringPrefixCaseInsensitive(prefix string) ([]*models.Entity, error)
+	QueryStringContains(text string) ([]*models.Entity, error)
+	QueryInt32Equal(value int32) ([]*models.Entity, error)
+	QueryInt64Greater(value int64) ([]*models.Entity, error)
+	QueryFloat64Between(min, max float64) ([]*models.Entity, error)
+	QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64) ([]*models.Entity, error)
+	QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64) ([]*models.Entity, error)
+	QueryInt64Ordered(descending bool, offset, limit int) ([]*models.Entity, error) // ordered by Int64, then Id
+
+	// single objects, each operation in its own transaction
+	Get(id uint64) (*models.Entity, error) // returns nil if the object doesn't exist
+	Put(item *models.Entity) error         // inserts (setting the ID) or updates the object
+	Remove(item *models.Entity) error
+
+	// count & aggregates
+	Count() (uint64, error)
+	CountInt64Greater(value int64) (uint64, error)
+	AggregateInt64() (Int64Aggregates, error)
+	AggregateInt64Greater(value int64) (Int64Aggregates, error)
+	AggregateFloat64() (Float64Aggregates, error)
+	AggregateFloat64Between(min, max float64) (Float64Aggregates, error)
+
+	// relations
+	PutCustomers(customers []*models.Customer) error // inserts customers including their orders
+	ReadCustomers() ([]*models.Customer, error)      // reads all customers, including their orders
+	QueryOrdersByCustomerName(name string) ([]*models.Order, error)
+	RemoveAllCustomers() error // removes all customers and all orders
+}
+
+// DurabilityLimited is implemented by databases which don't provide all durability levels. They run with the closest
+// level they do provide, which is printed with the results instead of the requested one.
+type DurabilityLimited interface {
+	EffectiveDurability() string
+}
+
+// Int64Aggregates holds the results of aggregate functions over the Int64 property
+type Int64Aggregates struct {
+	Count   uint64
+	Sum     int64
+	Min     int64
+	Max     int64
+	Average float64
+}
+
+// Float64Aggregates holds the results of aggregate functions over the Float64 property
+type Float64Aggregates struct {
+	Count   uint64
+	Sum     float64
+	Min     float64
+	Max     float64
+	Average float64
 }
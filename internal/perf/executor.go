@@ -17,13 +17,17 @@
 package perf
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/objectbox/objectbox-go-performance/internal/models"
 	"github.com/pkg/profile"
 	"log"
+	"math"
+	"math/rand"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"time"
 )
@@ -35,14 +39,39 @@ func assert(err error) {
 }
 
 type Executor struct {
-	exec  Executable
-	times map[string][]time.Duration // arrays of runtimes indexed by function name
+	exec    Executable
+	samples map[string][]sample // arrays of measurements indexed by function name
+
+	latencies []time.Duration // single-operation latencies of the currently tracked function, see startOps()
+
+	modelFunctions []string                      // functions of the test suite, in the order they're printed
+	modelAverages  map[string]map[string]float64 // average ms by model & function, see printModelComparison()
+}
+
+// measurement holds the state at the beginning of a tracked function
+type measurement struct {
+	time    time.Time
+	objects int
+	mallocs uint64
+	bytes   uint64
+}
+
+// sample is a single finished measurement of a tracked function
+type sample struct {
+	duration time.Duration
+	objects  int
+	mallocs  uint64
+	bytes    uint64
+
+	latencies []time.Duration    // only tracked by functions executing single-object operations
+	metrics   map[string]float64 // additional values indexed by unit, see setMetric()
 }
 
 func CreateExecutor(executable Executable) *Executor {
 	var result = &Executor{
-		times: map[string][]time.Duration{},
-		exec:  executable,
+		samples:       map[string][]sample{},
+		modelAverages: map[string]map[string]float64{},
+		exec:          executable,
 	}
 
 	result.Init()
@@ -50,12 +79,12 @@ func CreateExecutor(executable Executable) *Executor {
 }
 
 func (perf *Executor) Init() {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(0))
 	assert(perf.exec.Init())
 }
 
 func (perf *Executor) Close() {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(0))
 	assert(perf.exec.Close())
 }
 
@@ -70,7 +99,82 @@ func (perf *Executor) Run(options Options) {
 		defer profile.Start().Stop()
 	}
 
-	log.Printf("running the test %d times with %d objects", options.Runs, options.Count)
+	if options.Format != FormatTable && options.Format != FormatBenchstat {
+		panic(fmt.Errorf("unknown output format %q", options.Format))
+	} else if options.Count <= 0 {
+		panic(fmt.Errorf("invalid number of objects %d", options.Count))
+	} else if options.PageSize <= 0 {
+		panic(fmt.Errorf("invalid page size %d", options.PageSize))
+	} else if options.GetManySelection != SelectionRandom && options.GetManySelection != SelectionSequential {
+		panic(fmt.Errorf("unknown ID selection %q", options.GetManySelection))
+	} else if len(options.Models) == 0 {
+		panic(fmt.Errorf("no entity models to run the tests with"))
+	}
+
+	for _, size := range options.GetManySizes {
+		if size <= 0 {
+			panic(fmt.Errorf("invalid number of IDs to look up at once %d", size))
+		}
+	}
+
+	if options.Readers < 0 {
+		panic(fmt.Errorf("invalid number of readers %d", options.Readers))
+	} else if options.ConcurrentDuration <= 0 {
+		panic(fmt.Errorf("invalid duration of concurrent tests %v", options.ConcurrentDuration))
+	} else if options.MixedWorkers < 0 {
+		panic(fmt.Errorf("invalid number of mixed workload goroutines %d", options.MixedWorkers))
+	} else if options.ReadRatio < 0 || options.ReadRatio > 1 {
+		panic(fmt.Errorf("invalid read ratio %v, must be between 0 and 1", options.ReadRatio))
+	} else if options.YcsbOperations < 0 {
+		panic(fmt.Errorf("invalid number of YCSB operations %d", options.YcsbOperations))
+	} else if options.YcsbThreads <= 0 {
+		panic(fmt.Errorf("invalid number of YCSB threads %d", options.YcsbThreads))
+	} else if options.ScaleCount < 0 {
+		panic(fmt.Errorf("invalid number of objects of the large dataset test %d", options.ScaleCount))
+	} else if options.ChunkSize <= 0 {
+		panic(fmt.Errorf("invalid chunk size %d", options.ChunkSize))
+	} else if options.Durability != DurabilityFull && options.Durability != DurabilityNormal &&
+		options.Durability != DurabilityNone {
+		panic(fmt.Errorf("unknown durability level %q", options.Durability))
+	} else if options.BatchSize < 0 {
+		panic(fmt.Errorf("invalid batch size %d", options.BatchSize))
+	} else if options.CrashIterations < 0 {
+		panic(fmt.Errorf("invalid number of crash consistency iterations %d", options.CrashIterations))
+	} else if options.CrashMaxDelay <= 0 {
+		panic(fmt.Errorf("invalid maximum delay of the crash consistency test %v", options.CrashMaxDelay))
+	} else if options.GrowthSteps < 0 || options.GrowthSteps > options.Count {
+		panic(fmt.Errorf("invalid number of growth profile steps %d, must be between 0 and the number of objects",
+			options.GrowthSteps))
+	} else if options.Existing && options.ScaleCount > 0 {
+		panic(fmt.Errorf("the existing database test and the large dataset test can't be combined"))
+	}
+
+	for _, size := range options.BatchSweep {
+		if size <= 0 {
+			panic(fmt.Errorf("invalid batch size %d", size))
+		}
+	}
+
+	for _, workload := range options.YcsbWorkloads {
+		if _, ok := ycsbWorkloads[workload]; !ok {
+			panic(fmt.Errorf("unknown YCSB workload %q", workload))
+		}
+	}
+
+	if options.CrashChild {
+		// the standard output is used to report the committed transactions, nothing else may be printed there
+		assert(perf.exec.SetModel(options.Models[0]))
+		perf.runCrashChild(options)
+		return
+	}
+
+	if options.Existing {
+		log.Printf("running the existing database test %d times", options.Runs)
+	} else if options.ScaleCount > 0 {
+		log.Printf("running the large dataset test %d times with %d objects", options.Runs, options.ScaleCount)
+	} else {
+		log.Printf("running the test %d times with %d objects", options.Runs, options.Count)
+	}
 
 	if options.ManualGc {
 		// disable automatic garbage collector
@@ -78,11 +182,146 @@ func (perf *Executor) Run(options Options) {
 		debug.SetGCPercent(-1)
 	}
 
-	var inserts = perf.PrepareData(options.Count)
+	var durability = options.Durability
+	if limited, ok := perf.exec.(DurabilityLimited); ok && limited.EffectiveDurability() != durability {
+		durability = limited.EffectiveDurability()
+		log.Printf("durability level %s is not supported, running with %s", options.Durability, durability)
+	}
+
+	if options.Format == FormatBenchstat {
+		fmt.Printf("goos: %s\n", runtime.GOOS)
+		fmt.Printf("goarch: %s\n", runtime.GOARCH)
+		fmt.Printf("durability: %s\n", durability)
+		if len(options.BackendOptions) > 0 {
+			fmt.Printf("backend-opt: %s\n", backendOptionsString(options.BackendOptions))
+		}
+	} else {
+		if durability != options.Durability {
+			fmt.Printf("Durability: %s (requested %s)\n", durability, options.Durability)
+		} else {
+			fmt.Printf("Durability: %s\n", durability)
+		}
+		if len(options.BackendOptions) > 0 {
+			fmt.Printf("Backend options: %s\n", backendOptionsString(options.BackendOptions))
+		}
+	}
+
+	if options.Existing {
+		for _, model := range options.Models {
+			log.Printf("running the existing database test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runExisting(options, model)
+		}
+		return
+	}
+
+	if options.ScaleCount > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the large dataset test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runScale(options, model)
+		}
+		return
+	}
+
+	for _, model := range options.Models {
+		log.Printf("running the test with the %s model", model)
+		assert(perf.exec.SetModel(model))
+
+		if len(options.BytesSweep) == 0 {
+			perf.run(options, model)
+			continue
+		}
+
+		// run the whole test suite for each payload size to see how the results change with the object size
+		for _, length := range options.BytesSweep {
+			log.Printf("running the test with %d bytes payload", length)
+			var sweepOptions = options
+			sweepOptions.BytesMinLength = length
+			sweepOptions.BytesMaxLength = length
+			perf.run(sweepOptions, model)
+		}
+	}
+
+	if len(options.BytesSweep) == 0 && options.Format != FormatBenchstat {
+		perf.printModelComparison(ModelPlain, ModelIndexed)
+	}
+
+	if len(options.BatchSweep) > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the batch size sweep with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runBatchSweep(options, model)
+		}
+	}
+
+	if options.Readers > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the concurrent readers test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runConcurrentReaders(options, model)
+		}
+	}
+
+	if options.MixedWorkers > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the mixed workload test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runMixed(options, model)
+		}
+	}
+
+	if len(options.YcsbWorkloads) > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the YCSB test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runYcsb(options, model)
+		}
+	}
+
+	if options.GrowthSteps > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the growth profile test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runGrowth(options, model)
+		}
+	}
+
+	if options.CrashIterations > 0 {
+		for _, model := range options.Models {
+			log.Printf("running the crash consistency test with the %s model", model)
+			assert(perf.exec.SetModel(model))
+			perf.runCrash(options, model)
+		}
+	}
+
+	if options.OrdersPerCustomer > 0 {
+		perf.runRelations(options)
+	}
+}
+
+// backendOptionsString formats the backend options as sorted key=value pairs
+func backendOptionsString(values map[string]string) string {
+	var pairs []string
+	for key, value := range values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// run executes the test suite with a single configuration and prints the results
+func (perf *Executor) run(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var inserts = perf.PrepareData(gen, options.Count)
+	var objectSize = averageObjectSize(inserts)
 	var size uint64
+	var rnd = rand.New(rand.NewSource(options.Seed))
 
 	for i := 0; i < options.Runs; i++ {
-		perf.PutBulk(inserts)
+		perf.PutBulk(inserts, options.BatchSize)
 		items := perf.ReadAll(options.Count)
 		perf.UpdateBulk(items)
 
@@ -93,12 +332,21 @@ func (perf *Executor) Run(options Options) {
 		}
 
 		if len(items) >= 100 {
-			perf.Query100IdsBetween(items[len(items)-100].Id, items[len(items)-1].Id)
+			var min, max = items[len(items)-100].Id, items[len(items)-1].Id
+			var expectedIdMatches = 0
+			for _, object := range inserts {
+				if object.Id >= min && object.Id <= max {
+					expectedIdMatches++
+				}
+			}
+			perf.Query100IdsBetween(min, max, expectedIdMatches)
 		}
 
+		perf.runGetMany(items, options, rnd)
+
 		var prefix = "Entity no. 1"
 		var expectedPrefixMatches = 0
-		for _, object := range items {
+		for _, object := range inserts {
 			if strings.HasPrefix(object.String, prefix) {
 				expectedPrefixMatches++
 			}
@@ -106,13 +354,32 @@ func (perf *Executor) Run(options Options) {
 		log.Printf("QueryStringPrefix must match %d items", expectedPrefixMatches)
 		perf.QueryStringPrefix(prefix, expectedPrefixMatches)
 
-		perf.RemoveAll()
+		perf.runQueries(inserts)
+
+		var ordered = sortedByInt64(inserts)
+		perf.QueryPagesInt64Asc(ordered, options.PageSize)
+		perf.QueryPagesInt64Desc(ordered, options.PageSize)
+
+		perf.runAggregates(inserts)
+
+		// after all the warm reads, so that they aren't run on a freshly reopened database
+		perf.runColdReads(items)
+
+		perf.RemoveAll(len(items))
 
 		// insert again and delete by id
 		removeIds(inserts)
-		perf.PutBulk(inserts)
+		perf.PutBulk(inserts, options.BatchSize)
 		perf.RemoveBulk(inserts)
 
+		// insert again asynchronously and check that the objects survive reopening the database
+		removeIds(inserts)
+		perf.runPersistence(inserts)
+
+		if options.SingleOps > 0 {
+			perf.runSingle(inserts, options.SingleOps)
+		}
+
 		log.Printf("%d/%d finished", i+1, options.Runs)
 
 		if options.ManualGc {
@@ -122,22 +389,353 @@ func (perf *Executor) Run(options Options) {
 		}
 	}
 
-	perf.PrintTimes([]string{
+	var functions = []string{
 		"Init",
 		"PutBulk",
 		"ReadAll",
 		"UpdateBulk",
 		"RemoveAll",
 		"RemoveBulk",
+		"Reopen",
+		"ColdGet",
+		"ColdReadAll",
+		"ColdQueryInt32Equal",
+		"PutAsync",
+		"VerifyPersisted",
 		"Query100IdsBetween",
+	}
+
+	for _, size := range options.GetManySizes {
+		functions = append(functions, getManyFunction(size, options.GetManySelection))
+	}
+
+	functions = append(functions,
 		"QueryStringPrefix",
-	})
+		"QueryStringPrefixCaseInsensitive",
+		"QueryStringContains",
+		"QueryInt32Equal",
+		"QueryInt64Greater",
+		"QueryFloat64Between",
+		"QueryInt32EqualAndInt64Greater",
+		"QueryInt32EqualOrFloat64Between",
+		"QueryPagesInt64Asc",
+		"QueryPagesInt64Desc",
+		"Count",
+		"CountInt64Greater",
+		"AggregateInt64",
+		"AggregateInt64Greater",
+		"AggregateFloat64",
+		"AggregateFloat64Between",
+		"PutSingle",
+		"GetSingle",
+		"UpdateSingle",
+		"RemoveSingle",
+	)
+
+	perf.modelFunctions = functions
+	perf.modelAverages[model] = perf.averageTimes(functions)
 
-	fmt.Println(fmt.Sprintf("DB size after update, before remove: %d", size))
+	perf.printResults(options, functions,
+		fmt.Sprintf("Model: %s, objects: %d, average object size: %d bytes", model, options.Count, objectSize),
+		fmt.Sprintf("model=%s/count=%d/size=%d", model, options.Count, objectSize),
+		objectSize, size)
 }
 
-func (perf *Executor) RemoveAll() {
-	defer perf.trackTime(time.Now())
+// runColdReads closes and reopens the database before reading, so that the reads don't profit from the database
+// caches filled by the previous operations. Note: the operating system caches the files regardless.
+func (perf *Executor) runColdReads(items []*models.Entity) {
+	if len(items) == 0 {
+		return
+	}
+
+	var sample = items[len(items)/2]
+
+	perf.Reopen()
+	perf.ColdGet(sample)
+	perf.ColdReadAll(len(items))
+
+	perf.Reopen()
+	perf.ColdQueryInt32Equal(sample.Int32, selectIds(items, func(object *models.Entity) bool {
+		return object.Int32 == sample.Int32
+	}))
+}
+
+// runPersistence inserts the objects asynchronously, closes and reopens the database and verifies all the objects
+func (perf *Executor) runPersistence(inserts []*models.Entity) {
+	perf.PutAsync(inserts)
+	perf.Reopen()
+	perf.VerifyPersisted(inserts)
+	assert(perf.exec.RemoveAll())
+}
+
+// runGetMany looks up all the objects by their IDs, in sets of each of the configured sizes
+func (perf *Executor) runGetMany(items []*models.Entity, options Options, rnd *rand.Rand) {
+	var ids = make([]uint64, len(items))
+	for i, object := range items {
+		ids[i] = object.Id
+	}
+
+	if options.GetManySelection == SelectionRandom {
+		rnd.Shuffle(len(ids), func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+		})
+	} else {
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+	}
+
+	for _, size := range options.GetManySizes {
+		var sets [][]uint64
+		var expected []idSet
+		for i := 0; i < len(ids); i += size {
+			var end = i + size
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			var set = ids[i:end]
+			sets = append(sets, set)
+			expected = append(expected, idSet{})
+			for _, id := range set {
+				expected[len(expected)-1][id] = true
+			}
+		}
+
+		perf.GetMany(getManyFunction(size, options.GetManySelection), sets, expected, len(ids))
+	}
+}
+
+// getManyFunction returns the name under which the GetMany measurements with the given parameters are tracked
+func getManyFunction(size int, selection string) string {
+	return fmt.Sprintf("GetMany/ids=%d/selection=%s", size, selection)
+}
+
+// runQueries executes the queries with parameters chosen so that they match some of the inserted objects
+func (perf *Executor) runQueries(inserts []*models.Entity) {
+	var sample = inserts[len(inserts)/2]
+	var count = float64(len(inserts))
+
+	// case-insensitive: "entity no. 1" matches the same objects as "Entity no. 1" because the rest of the string
+	// (the number and the random lower-case letters) doesn't have upper-case characters
+	var lowerPrefix = "entity no. 1"
+	perf.QueryStringPrefixCaseInsensitive(lowerPrefix, selectIds(inserts, func(object *models.Entity) bool {
+		return strings.HasPrefix(strings.ToLower(object.String), lowerPrefix)
+	}))
+
+	// random letters at the end of the string of the sample object
+	var text = sample.String[len(sample.String)-2:]
+	perf.QueryStringContains(text, selectIds(inserts, func(object *models.Entity) bool {
+		return strings.Contains(object.String, text)
+	}))
+
+	perf.QueryInt32Equal(sample.Int32, selectIds(inserts, func(object *models.Entity) bool {
+		return object.Int32 == sample.Int32
+	}))
+
+	// values are generated in range [0, count), select (roughly) the top 10 %
+	var int64Value = int64(count * 0.9)
+	perf.QueryInt64Greater(int64Value, selectIds(inserts, func(object *models.Entity) bool {
+		return object.Int64 > int64Value
+	}))
+
+	// roughly 1 % of the values around the sample
+	var min, max = sample.Float64, sample.Float64 + count/100
+	perf.QueryFloat64Between(min, max, selectIds(inserts, func(object *models.Entity) bool {
+		return object.Float64 >= min && object.Float64 <= max
+	}))
+
+	var int64Half = int64(count * 0.5)
+	perf.QueryInt32EqualAndInt64Greater(sample.Int32, int64Half, selectIds(inserts, func(object *models.Entity) bool {
+		return object.Int32 == sample.Int32 && object.Int64 > int64Half
+	}))
+
+	perf.QueryInt32EqualOrFloat64Between(sample.Int32, min, max, selectIds(inserts, func(object *models.Entity) bool {
+		return object.Int32 == sample.Int32 || (object.Float64 >= min && object.Float64 <= max)
+	}))
+}
+
+// runAggregates executes the count & aggregate functions, with and without conditions
+func (perf *Executor) runAggregates(inserts []*models.Entity) {
+	var count = float64(len(inserts))
+	var all = func(object *models.Entity) bool {
+		return true
+	}
+
+	// values are generated in range [0, count), select (roughly) the top 10 %
+	var int64Value = int64(count * 0.9)
+	var int64Greater = func(object *models.Entity) bool {
+		return object.Int64 > int64Value
+	}
+
+	// roughly 10 % of the values in the middle of the range
+	var min, max = count * 0.45, count * 0.55
+	var float64Between = func(object *models.Entity) bool {
+		return object.Float64 >= min && object.Float64 <= max
+	}
+
+	perf.Count(int64Aggregates(inserts, all).Count)
+	perf.CountInt64Greater(int64Value, int64Aggregates(inserts, int64Greater).Count)
+	perf.AggregateInt64(int64Aggregates(inserts, all))
+	perf.AggregateInt64Greater(int64Value, int64Aggregates(inserts, int64Greater))
+	perf.AggregateFloat64(float64Aggregates(inserts, all))
+	perf.AggregateFloat64Between(min, max, float64Aggregates(inserts, float64Between))
+}
+
+// runSingle executes the single-object operations on (at most) the given number of the inserted objects
+func (perf *Executor) runSingle(inserts []*models.Entity, count int) {
+	if count > len(inserts) {
+		count = len(inserts)
+	}
+
+	var items = inserts[:count]
+	removeIds(items)
+	perf.PutSingle(items)
+	perf.GetSingle(items)
+	perf.UpdateSingle(items)
+	perf.RemoveSingle(items)
+}
+
+// runRelations executes the relations test suite and prints the results
+func (perf *Executor) runRelations(options Options) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var count = options.Count / options.OrdersPerCustomer
+	if count == 0 {
+		count = 1
+	}
+
+	var customers = perf.PrepareCustomers(gen, count, options.OrdersPerCustomer)
+	var size uint64
+
+	var name = customers[0].Name
+	var expectedOrders = 0
+	for _, customer := range customers {
+		if customer.Name == name {
+			expectedOrders += len(customer.Orders)
+		}
+	}
+	log.Printf("QueryOrdersByCustomerName must match %d items", expectedOrders)
+
+	for i := 0; i < options.Runs; i++ {
+		perf.PutCustomers(customers)
+		perf.ReadCustomers(customers)
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		perf.QueryOrdersByCustomerName(name, expectedOrders)
+		perf.RemoveAllCustomers(len(customers))
+		removeCustomerIds(customers)
+
+		log.Printf("%d/%d relations finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions = []string{
+		"PutCustomers",
+		"ReadCustomers",
+		"QueryOrdersByCustomerName",
+		"RemoveAllCustomers",
+	}
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("Relations: customers: %d, orders per customer: %d", count, options.OrdersPerCustomer),
+		fmt.Sprintf("customers=%d/orders=%d", count, options.OrdersPerCustomer),
+		0, size)
+}
+
+// printResults prints the results of the given functions in the configured format and resets the measurements
+// so that the next configuration starts from scratch
+func (perf *Executor) printResults(options Options, functions []string, title, config string, objectSize int,
+	dbSize uint64) {
+	if options.Format == FormatBenchstat {
+		perf.PrintBenchstat(functions, config, objectSize)
+		fmt.Printf("BenchmarkDBSize/%s-%d\t1\t%d bytes\n", config, runtime.GOMAXPROCS(0), dbSize)
+	} else {
+		fmt.Println(title)
+		perf.PrintTimes(functions)
+		fmt.Println(fmt.Sprintf("DB size after update, before remove: %d", dbSize))
+	}
+
+	perf.samples = map[string][]sample{}
+}
+
+// Reopen closes the database and opens it again, keeping the data; only the opening is measured
+func (perf *Executor) Reopen() {
+	assert(perf.exec.Release())
+
+	defer perf.trackTime(perf.start(0))
+	assert(perf.exec.Open())
+}
+
+// ColdGet reads a single object right after the database was opened, i.e. the first-read latency
+func (perf *Executor) ColdGet(expected *models.Entity) {
+	defer perf.trackTime(perf.start(1))
+	if item, err := perf.exec.Get(expected.Id); err != nil {
+		panic(err)
+	} else if item == nil {
+		panic(fmt.Errorf("object %d not found", expected.Id))
+	} else if !entityEquals(item, expected) {
+		panic(fmt.Errorf("object %d read differs from the one written: %+v instead of %+v", expected.Id, item,
+			expected))
+	}
+}
+
+// VerifyPersisted reads all objects and checks them field by field against the ones written
+func (perf *Executor) VerifyPersisted(expected []*models.Entity) {
+	defer perf.trackTime(perf.start(len(expected)))
+
+	items, err := perf.exec.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	var byId = make(map[uint64]*models.Entity, len(items))
+	for _, item := range items {
+		byId[item.Id] = item
+	}
+
+	for _, object := range expected {
+		if item, found := byId[object.Id]; !found {
+			panic(fmt.Errorf("object %d was not persisted", object.Id))
+		} else if !entityEquals(item, object) {
+			panic(fmt.Errorf("object %d persisted differs from the one written: %+v instead of %+v", object.Id, item,
+				object))
+		}
+	}
+
+	if len(items) != len(expected) {
+		panic(fmt.Errorf("invalid number of objects persisted - %d instead of %d", len(items), len(expected)))
+	}
+}
+
+func (perf *Executor) ColdReadAll(expectedCount int) {
+	defer perf.trackTime(perf.start(expectedCount))
+	if items, err := perf.exec.ReadAll(); err != nil {
+		panic(err)
+	} else if len(items) != expectedCount {
+		panic("invalid number of objects read")
+	}
+}
+
+func (perf *Executor) ColdQueryInt32Equal(value int32, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryInt32Equal(value)
+	checkQueryResults("ColdQueryInt32Equal", items, err, expected)
+}
+
+func (perf *Executor) RemoveAll(expectedCount int) {
+	defer perf.trackTime(perf.start(expectedCount))
 	err := perf.exec.RemoveAll()
 	if err != nil {
 		panic(err)
@@ -145,28 +743,84 @@ func (perf *Executor) RemoveAll() {
 }
 
 func (perf *Executor) RemoveBulk(items []*models.Entity) {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(len(items)))
 	assert(perf.exec.RemoveBulk(items))
 }
 
-func (perf *Executor) PrepareData(count int) []*models.Entity {
-	defer perf.trackTime(time.Now())
+func (perf *Executor) PrepareData(gen *Generator, count int) []*models.Entity {
+	defer perf.trackTime(perf.start(count))
+	return gen.Entities(count)
+}
 
-	var result = make([]*models.Entity, count)
-	for i := 0; i < count; i++ {
-		result[i] = &models.Entity{
-			String:  fmt.Sprintf("Entity no. %d", i),
-			Float64: float64(i),
-			Int32:   int32(i),
-			Int64:   int64(i),
+func (perf *Executor) PrepareCustomers(gen *Generator, count, ordersPerCustomer int) []*models.Customer {
+	defer perf.trackTime(perf.start(count * (1 + ordersPerCustomer)))
+	return gen.Customers(count, ordersPerCustomer)
+}
+
+func removeCustomerIds(customers []*models.Customer) {
+	for _, customer := range customers {
+		customer.Id = 0
+		for _, order := range customer.Orders {
+			order.Id = 0
+			order.CustomerId = 0
 		}
 	}
+}
 
-	return result
+// PutCustomers inserts the customers including their orders
+func (perf *Executor) PutCustomers(customers []*models.Customer) {
+	defer perf.trackTime(perf.start(len(customers)))
+	assert(perf.exec.PutCustomers(customers))
+}
+
+// ReadCustomers reads all customers, eagerly loading their orders, and checks them against the given ones
+func (perf *Executor) ReadCustomers(expected []*models.Customer) {
+	defer perf.trackTime(perf.start(len(expected)))
+
+	items, err := perf.exec.ReadCustomers()
+	if err != nil {
+		panic(err)
+	} else if len(items) != len(expected) {
+		panic(fmt.Errorf("invalid number of customers read - %d instead of %d", len(items), len(expected)))
+	}
+
+	var expectedOrders = make(map[uint64]int, len(expected))
+	for _, customer := range expected {
+		expectedOrders[customer.Id] = len(customer.Orders)
+	}
+
+	for _, customer := range items {
+		if len(customer.Orders) != expectedOrders[customer.Id] {
+			panic(fmt.Errorf("invalid number of orders read for customer %d - %d instead of %d", customer.Id,
+				len(customer.Orders), expectedOrders[customer.Id]))
+		}
+		for _, order := range customer.Orders {
+			if order.CustomerId != customer.Id {
+				panic(fmt.Errorf("order %d loaded for customer %d belongs to customer %d", order.Id, customer.Id,
+					order.CustomerId))
+			}
+		}
+	}
+}
+
+func (perf *Executor) QueryOrdersByCustomerName(name string, expectedCount int) {
+	defer perf.trackTime(perf.start(expectedCount))
+	if items, err := perf.exec.QueryOrdersByCustomerName(name); err != nil {
+		panic(err)
+	} else if len(items) != expectedCount {
+		panic(fmt.Errorf("invalid number of objects returned by QueryOrdersByCustomerName - %d instead of %d",
+			len(items), expectedCount))
+	}
+}
+
+// RemoveAllCustomers removes all customers and all orders
+func (perf *Executor) RemoveAllCustomers(expectedCount int) {
+	defer perf.trackTime(perf.start(expectedCount))
+	assert(perf.exec.RemoveAllCustomers())
 }
 
 func (perf *Executor) PutAsync(items []*models.Entity) {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(len(items)))
 
 	for _, item := range items {
 		assert(perf.exec.PutAsync(item))
@@ -175,13 +829,14 @@ func (perf *Executor) PutAsync(items []*models.Entity) {
 	assert(perf.exec.AwaitAsyncCompletion())
 }
 
-func (perf *Executor) PutBulk(items []*models.Entity) {
-	defer perf.trackTime(time.Now())
-	assert(perf.exec.PutBulk(items))
+// PutBulk inserts the objects in transactions of the given batch size, all at once if batchSize is 0
+func (perf *Executor) PutBulk(items []*models.Entity, batchSize int) {
+	defer perf.trackTime(perf.start(len(items)))
+	perf.putBatches(items, batchSize)
 }
 
 func (perf *Executor) ReadAll(expectedCount int) []*models.Entity {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(expectedCount))
 
 	if items, err := perf.exec.ReadAll(); err != nil {
 		panic(err)
@@ -193,7 +848,7 @@ func (perf *Executor) ReadAll(expectedCount int) []*models.Entity {
 }
 
 func (perf *Executor) ChangeValues(items []*models.Entity) {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(len(items)))
 
 	count := len(items)
 	for i := 0; i < count; i++ {
@@ -202,22 +857,89 @@ func (perf *Executor) ChangeValues(items []*models.Entity) {
 }
 
 func (perf *Executor) UpdateBulk(items []*models.Entity) {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(len(items)))
 	assert(perf.exec.PutBulk(items))
 }
 
-func (perf *Executor) Query100IdsBetween(min, max uint64) {
-	defer perf.trackTime(time.Now())
+// PutSingle inserts the objects one by one, each in its own transaction
+func (perf *Executor) PutSingle(items []*models.Entity) {
+	defer perf.trackTime(perf.startOps(len(items), len(items)))
+	for _, item := range items {
+		var start = time.Now()
+		assert(perf.exec.Put(item))
+		perf.trackLatency(start)
+	}
+}
+
+// GetSingle reads the objects one by one by their IDs and checks them against the given ones
+func (perf *Executor) GetSingle(expected []*models.Entity) {
+	defer perf.trackTime(perf.startOps(len(expected), len(expected)))
+	for _, object := range expected {
+		var start = time.Now()
+		item, err := perf.exec.Get(object.Id)
+		perf.trackLatency(start)
+
+		if err != nil {
+			panic(err)
+		} else if item == nil {
+			panic(fmt.Errorf("object %d not found", object.Id))
+		} else if !entityEquals(item, object) {
+			panic(fmt.Errorf("object %d read differs from the one written: %+v instead of %+v", object.Id, item, object))
+		}
+	}
+}
+
+// UpdateSingle updates the objects one by one, each in its own transaction
+func (perf *Executor) UpdateSingle(items []*models.Entity) {
+	defer perf.trackTime(perf.startOps(len(items), len(items)))
+	for _, item := range items {
+		var start = time.Now()
+		assert(perf.exec.Put(item))
+		perf.trackLatency(start)
+	}
+}
+
+// RemoveSingle removes the objects one by one, each in its own transaction
+func (perf *Executor) RemoveSingle(items []*models.Entity) {
+	defer perf.trackTime(perf.startOps(len(items), len(items)))
+	for _, item := range items {
+		var start = time.Now()
+		assert(perf.exec.Remove(item))
+		perf.trackLatency(start)
+	}
+}
+
+// entityEquals compares all fields of the given objects
+func entityEquals(a, b *models.Entity) bool {
+	// bytes.Equal() treats nil and an empty slice the same, which is what some databases return for an empty value
+	return a.Id == b.Id && a.Int32 == b.Int32 && a.Int64 == b.Int64 && a.String == b.String &&
+		a.Float64 == b.Float64 && bytes.Equal(a.Bytes, b.Bytes)
+}
+
+// GetMany looks up the objects by each of the given sets of IDs, checking the results against the expected sets
+func (perf *Executor) GetMany(fun string, sets [][]uint64, expected []idSet, objects int) {
+	defer perf.trackTimeAs(fun, perf.startOps(len(sets), objects))
+	for i, ids := range sets {
+		var start = time.Now()
+		items, err := perf.exec.GetMany(ids)
+		perf.trackLatency(start)
+
+		checkQueryResults("GetMany", items, err, expected[i])
+	}
+}
+
+func (perf *Executor) Query100IdsBetween(min, max uint64, expectedCount int) {
+	defer perf.trackTime(perf.start(expectedCount))
 	if items, err := perf.exec.QueryIdBetween(min, max); err != nil {
 		panic(err)
-	} else if uint64(len(items)) != max-min+1 {
-		panic(fmt.Errorf("invalid number of objects returned by QueryIdBetween(%d, %d): %d", min, max,
-			len(items)))
+	} else if len(items) != expectedCount {
+		panic(fmt.Errorf("invalid number of objects returned by QueryIdBetween(%d, %d) - %d instead of %d",
+			min, max, len(items), expectedCount))
 	}
 }
 
 func (perf *Executor) QueryStringPrefix(prefix string, expectedCount int) {
-	defer perf.trackTime(time.Now())
+	defer perf.trackTime(perf.start(expectedCount))
 	if items, err := perf.exec.QueryStringPrefix(prefix); err != nil {
 		panic(err)
 	} else if len(items) != expectedCount {
@@ -226,12 +948,361 @@ func (perf *Executor) QueryStringPrefix(prefix string, expectedCount int) {
 	}
 }
 
-func (perf *Executor) trackTime(start time.Time) {
-	elapsed := time.Since(start)
+// start begins a measurement of a function processing the given number of objects, see trackTime()
+func (perf *Executor) start(objects int) measurement {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	// take the time last so that reading the memory stats isn't included in the measurement
+	return measurement{
+		objects: objects,
+		mallocs: mem.Mallocs,
+		bytes:   mem.TotalAlloc,
+		time:    time.Now(),
+	}
+}
+
+// startOps begins a measurement of a function executing the given number of operations, processing the given
+// number of objects in total; each operation is additionally timed by trackLatency()
+func (perf *Executor) startOps(operations, objects int) measurement {
+	// allocate in advance so that tracking the latencies doesn't affect the memory stats of the measurement
+	perf.latencies = make([]time.Duration, 0, operations)
+	return perf.start(objects)
+}
+
+// trackLatency records the latency of a single operation which started at the given time
+func (perf *Executor) trackLatency(start time.Time) {
+	perf.latencies = append(perf.latencies, time.Since(start))
+}
+
+// idSet contains IDs of the objects expected to be returned by a query
+type idSet map[uint64]bool
+
+// selectIds returns IDs of the objects matching the given predicate
+func selectIds(items []*models.Entity, predicate func(object *models.Entity) bool) idSet {
+	var result = idSet{}
+	for _, object := range items {
+		if predicate(object) {
+			result[object.Id] = true
+		}
+	}
+	return result
+}
+
+// checkQueryResults panics if the query failed or if it didn't return exactly the expected objects
+func checkQueryResults(query string, items []*models.Entity, err error, expected idSet) {
+	if err != nil {
+		panic(err)
+	} else if len(items) != len(expected) {
+		panic(fmt.Errorf("invalid number of objects returned by %s - %d instead of %d", query, len(items),
+			len(expected)))
+	}
+
+	for _, object := range items {
+		if !expected[object.Id] {
+			panic(fmt.Errorf("object %d returned by %s doesn't match the query", object.Id, query))
+		}
+	}
+}
+
+func (perf *Executor) QueryStringPrefixCaseInsensitive(prefix string, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryStringPrefixCaseInsensitive(prefix)
+	checkQueryResults("QueryStringPrefixCaseInsensitive", items, err, expected)
+}
+
+func (perf *Executor) QueryStringContains(text string, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryStringContains(text)
+	checkQueryResults("QueryStringContains", items, err, expected)
+}
+
+func (perf *Executor) QueryInt32Equal(value int32, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryInt32Equal(value)
+	checkQueryResults("QueryInt32Equal", items, err, expected)
+}
+
+func (perf *Executor) QueryInt64Greater(value int64, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryInt64Greater(value)
+	checkQueryResults("QueryInt64Greater", items, err, expected)
+}
+
+func (perf *Executor) QueryFloat64Between(min, max float64, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryFloat64Between(min, max)
+	checkQueryResults("QueryFloat64Between", items, err, expected)
+}
+
+func (perf *Executor) QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryInt32EqualAndInt64Greater(int32Value, int64Value)
+	checkQueryResults("QueryInt32EqualAndInt64Greater", items, err, expected)
+}
+
+func (perf *Executor) QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64, expected idSet) {
+	defer perf.trackTime(perf.start(len(expected)))
+	items, err := perf.exec.QueryInt32EqualOrFloat64Between(int32Value, min, max)
+	checkQueryResults("QueryInt32EqualOrFloat64Between", items, err, expected)
+}
+
+// sortedByInt64 returns a copy of the given slice, sorted the same way as Executable.QueryInt64Ordered() ascending
+func sortedByInt64(items []*models.Entity) []*models.Entity {
+	var result = make([]*models.Entity, len(items))
+	copy(result, items)
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Int64 != result[j].Int64 {
+			return result[i].Int64 < result[j].Int64
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+// QueryPagesInt64Asc reads all objects ordered by Int64 ascending, page by page, checking the order and completeness
+func (perf *Executor) QueryPagesInt64Asc(ordered []*models.Entity, pageSize int) {
+	defer perf.trackTime(perf.start(len(ordered)))
+	perf.queryPages(ordered, pageSize, false)
+}
+
+// QueryPagesInt64Desc reads all objects ordered by Int64 descending, page by page, checking the order and completeness
+func (perf *Executor) QueryPagesInt64Desc(ordered []*models.Entity, pageSize int) {
+	defer perf.trackTime(perf.start(len(ordered)))
+	perf.queryPages(ordered, pageSize, true)
+}
+
+func (perf *Executor) queryPages(ordered []*models.Entity, pageSize int, descending bool) {
+	var count = len(ordered)
+	for offset := 0; offset < count; offset += pageSize {
+		page, err := perf.exec.QueryInt64Ordered(descending, offset, pageSize)
+		if err != nil {
+			panic(err)
+		}
+
+		var expectedLen = pageSize
+		if offset+pageSize > count {
+			expectedLen = count - offset
+		}
+
+		if len(page) != expectedLen {
+			panic(fmt.Errorf("invalid number of objects returned by QueryInt64Ordered(%v, %d, %d) - %d instead of %d",
+				descending, offset, pageSize, len(page), expectedLen))
+		}
+
+		for i, object := range page {
+			var expected = ordered[offset+i]
+			if descending {
+				expected = ordered[count-1-offset-i]
+			}
+
+			if object.Id != expected.Id {
+				panic(fmt.Errorf("invalid object returned by QueryInt64Ordered(%v, %d, %d) at position %d - "+
+					"ID %d instead of %d", descending, offset, pageSize, offset+i, object.Id, expected.Id))
+			}
+		}
+	}
+}
+
+// int64Aggregates computes the expected results of the aggregate functions over objects matching the predicate
+func int64Aggregates(items []*models.Entity, predicate func(object *models.Entity) bool) Int64Aggregates {
+	var result = Int64Aggregates{Min: math.MaxInt64, Max: math.MinInt64}
+	for _, object := range items {
+		if predicate(object) {
+			result.Count++
+			result.Sum += object.Int64
+			if object.Int64 < result.Min {
+				result.Min = object.Int64
+			}
+			if object.Int64 > result.Max {
+				result.Max = object.Int64
+			}
+		}
+	}
+
+	if result.Count > 0 {
+		result.Average = float64(result.Sum) / float64(result.Count)
+	}
+	return result
+}
+
+// float64Aggregates computes the expected results of the aggregate functions over objects matching the predicate
+func float64Aggregates(items []*models.Entity, predicate func(object *models.Entity) bool) Float64Aggregates {
+	var result = Float64Aggregates{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, object := range items {
+		if predicate(object) {
+			result.Count++
+			result.Sum += object.Float64
+			result.Min = math.Min(result.Min, object.Float64)
+			result.Max = math.Max(result.Max, object.Float64)
+		}
+	}
+
+	if result.Count > 0 {
+		result.Average = result.Sum / float64(result.Count)
+	}
+	return result
+}
+
+// floatEquals compares floating point values computed in a possibly different order, i.e. with different rounding
+func floatEquals(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func checkInt64Aggregates(function string, actual Int64Aggregates, err error, expected Int64Aggregates) {
+	if err != nil {
+		panic(err)
+	} else if actual.Count != expected.Count {
+		panic(fmt.Errorf("invalid count returned by %s - %d instead of %d", function, actual.Count, expected.Count))
+	} else if expected.Count == 0 {
+		return // other values are undefined for an empty set
+	} else if actual.Sum != expected.Sum || actual.Min != expected.Min || actual.Max != expected.Max ||
+		!floatEquals(actual.Average, expected.Average) {
+		panic(fmt.Errorf("invalid values returned by %s - %+v instead of %+v", function, actual, expected))
+	}
+}
+
+func checkFloat64Aggregates(function string, actual Float64Aggregates, err error, expected Float64Aggregates) {
+	if err != nil {
+		panic(err)
+	} else if actual.Count != expected.Count {
+		panic(fmt.Errorf("invalid count returned by %s - %d instead of %d", function, actual.Count, expected.Count))
+	} else if expected.Count == 0 {
+		return // other values are undefined for an empty set
+	} else if !floatEquals(actual.Sum, expected.Sum) || actual.Min != expected.Min || actual.Max != expected.Max ||
+		!floatEquals(actual.Average, expected.Average) {
+		panic(fmt.Errorf("invalid values returned by %s - %+v instead of %+v", function, actual, expected))
+	}
+}
+
+func (perf *Executor) Count(expectedCount uint64) {
+	defer perf.trackTime(perf.start(int(expectedCount)))
+	if count, err := perf.exec.Count(); err != nil {
+		panic(err)
+	} else if count != expectedCount {
+		panic(fmt.Errorf("invalid count returned by Count - %d instead of %d", count, expectedCount))
+	}
+}
+
+func (perf *Executor) CountInt64Greater(value int64, expectedCount uint64) {
+	defer perf.trackTime(perf.start(int(expectedCount)))
+	if count, err := perf.exec.CountInt64Greater(value); err != nil {
+		panic(err)
+	} else if count != expectedCount {
+		panic(fmt.Errorf("invalid count returned by CountInt64Greater - %d instead of %d", count, expectedCount))
+	}
+}
+
+func (perf *Executor) AggregateInt64(expected Int64Aggregates) {
+	defer perf.trackTime(perf.start(int(expected.Count)))
+	result, err := perf.exec.AggregateInt64()
+	checkInt64Aggregates("AggregateInt64", result, err, expected)
+}
+
+func (perf *Executor) AggregateInt64Greater(value int64, expected Int64Aggregates) {
+	defer perf.trackTime(perf.start(int(expected.Count)))
+	result, err := perf.exec.AggregateInt64Greater(value)
+	checkInt64Aggregates("AggregateInt64Greater", result, err, expected)
+}
+
+func (perf *Executor) AggregateFloat64(expected Float64Aggregates) {
+	defer perf.trackTime(perf.start(int(expected.Count)))
+	result, err := perf.exec.AggregateFloat64()
+	checkFloat64Aggregates("AggregateFloat64", result, err, expected)
+}
+
+func (perf *Executor) AggregateFloat64Between(min, max float64, expected Float64Aggregates) {
+	defer perf.trackTime(perf.start(int(expected.Count)))
+	result, err := perf.exec.AggregateFloat64Between(min, max)
+	checkFloat64Aggregates("AggregateFloat64Between", result, err, expected)
+}
+
+func (perf *Executor) trackTime(start measurement) {
+	elapsed := time.Since(start.time)
 
 	pc, _, _, _ := runtime.Caller(1)
-	fun := filepath.Ext(runtime.FuncForPC(pc).Name())[1:]
-	perf.times[fun] = append(perf.times[fun], elapsed)
+	perf.addSample(filepath.Ext(runtime.FuncForPC(pc).Name())[1:], start, elapsed)
+}
+
+// trackTimeAs finishes the measurement like trackTime() but stores it under the given name instead of the caller's
+func (perf *Executor) trackTimeAs(fun string, start measurement) {
+	perf.addSample(fun, start, time.Since(start.time))
+}
+
+func (perf *Executor) addSample(fun string, start measurement, elapsed time.Duration) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	perf.samples[fun] = append(perf.samples[fun], sample{
+		duration: elapsed,
+		objects:  start.objects,
+		mallocs:  mem.Mallocs - start.mallocs,
+		bytes:    mem.TotalAlloc - start.bytes,
+
+		latencies: perf.latencies,
+	})
+	perf.latencies = nil
+}
+
+// mergeSamples replaces the last given number of samples of the function by a single one, summing their values
+func (perf *Executor) mergeSamples(fun string, count int) {
+	var samples = perf.samples[fun]
+	var merged sample
+	for _, s := range samples[len(samples)-count:] {
+		merged.duration += s.duration
+		merged.objects += s.objects
+		merged.mallocs += s.mallocs
+		merged.bytes += s.bytes
+		merged.latencies = append(merged.latencies, s.latencies...)
+	}
+	perf.samples[fun] = append(samples[:len(samples)-count], merged)
+}
+
+// setMetric adds an additional value, reported with the given unit, to the last sample of the given function
+func (perf *Executor) setMetric(fun, unit string, value float64) {
+	var samples = perf.samples[fun]
+	var last = &samples[len(samples)-1]
+	if last.metrics == nil {
+		last.metrics = map[string]float64{}
+	}
+	last.metrics[unit] = value
+}
+
+// metricUnits returns the sorted units of the additional values of all the given samples
+func metricUnits(samples []sample) []string {
+	var units []string
+	var seen = map[string]bool{}
+	for _, s := range samples {
+		for unit := range s.metrics {
+			if !seen[unit] {
+				seen[unit] = true
+				units = append(units, unit)
+			}
+		}
+	}
+	sort.Strings(units)
+	return units
+}
+
+// percentile returns the latency which the given fraction (0..1] of the (sorted) latencies doesn't exceed
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	var i = int(math.Ceil(fraction*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// sortedLatencies returns the single-operation latencies of all the given samples, sorted ascending
+func sortedLatencies(samples []sample) []time.Duration {
+	var result []time.Duration
+	for _, s := range samples {
+		result = append(result, s.latencies...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result
 }
 
 func (perf *Executor) PrintTimes(functions []string) {
@@ -239,23 +1310,162 @@ func (perf *Executor) PrintTimes(functions []string) {
 	fmt.Println("Function\tRuns\tAverage ms\tAll times")
 
 	if len(functions) == 0 {
-		for fun := range perf.times {
+		for fun := range perf.samples {
 			functions = append(functions, fun)
 		}
 	}
 
 	for _, fun := range functions {
-		times := perf.times[fun]
+		samples := perf.samples[fun]
+		if len(samples) == 0 {
+			continue
+		}
 
 		sum := int64(0)
-		for _, duration := range times {
-			sum += duration.Nanoseconds()
+		for _, s := range samples {
+			sum += s.duration.Nanoseconds()
 		}
-		fmt.Printf("%s\t%d\t%f", fun, len(times), float64(sum/int64(len(times)))/1000000)
+		fmt.Printf("%s\t%d\t%f", fun, len(samples), float64(sum/int64(len(samples)))/1000000)
 
-		for _, duration := range times {
-			fmt.Printf("\t%f", float64(duration.Nanoseconds())/1000000)
+		for _, s := range samples {
+			fmt.Printf("\t%f", float64(s.duration.Nanoseconds())/1000000)
 		}
 		fmt.Println()
 	}
+
+	perf.printLatencies(functions)
+	perf.printMetrics(functions)
+}
+
+// averageTimes returns the average duration in milliseconds of each of the given functions which has any samples
+func (perf *Executor) averageTimes(functions []string) map[string]float64 {
+	var result = map[string]float64{}
+	for _, fun := range functions {
+		var samples = perf.samples[fun]
+		if len(samples) == 0 {
+			continue
+		}
+
+		var sum time.Duration
+		for _, s := range samples {
+			sum += s.duration
+		}
+		result[fun] = float64(sum.Nanoseconds()) / float64(len(samples)) / 1000000
+	}
+	return result
+}
+
+// printModelComparison prints the difference of the given model's results to the base model's, e.g. the cost of the
+// indexes on inserts & updates (delta) and their benefit for queries (ratio). Does nothing unless both models ran.
+func (perf *Executor) printModelComparison(base, model string) {
+	var baseAverages, averages = perf.modelAverages[base], perf.modelAverages[model]
+	if baseAverages == nil || averages == nil {
+		return
+	}
+
+	fmt.Printf("Model comparison: %s vs. %s\n", model, base)
+	fmt.Printf("Function\t%s ms\t%s ms\tDelta ms\tRatio\n", base, model)
+	for _, fun := range perf.modelFunctions {
+		baseAverage, found := baseAverages[fun]
+		if !found {
+			continue
+		}
+		average, found := averages[fun]
+		if !found {
+			continue
+		}
+
+		var ratio = math.NaN()
+		if baseAverage > 0 {
+			ratio = average / baseAverage
+		}
+		fmt.Printf("%s\t%f\t%f\t%+f\t%.2f\n", fun, baseAverage, average, average-baseAverage, ratio)
+	}
+}
+
+// printMetrics prints the averages of additional values, for the functions which track them
+func (perf *Executor) printMetrics(functions []string) {
+	var header = false
+	for _, fun := range functions {
+		var samples = perf.samples[fun]
+		for _, unit := range metricUnits(samples) {
+			if !header {
+				fmt.Println("Function\tMetric\tAverage\tAll values")
+				header = true
+			}
+
+			var sum, count = 0.0, 0
+			for _, s := range samples {
+				if value, ok := s.metrics[unit]; ok {
+					sum += value
+					count++
+				}
+			}
+			fmt.Printf("%s\t%s\t%f", fun, unit, sum/float64(count))
+
+			for _, s := range samples {
+				if value, ok := s.metrics[unit]; ok {
+					fmt.Printf("\t%f", value)
+				}
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// printLatencies prints percentiles of single-operation latencies, for the functions which track them
+func (perf *Executor) printLatencies(functions []string) {
+	var header = false
+	for _, fun := range functions {
+		var latencies = sortedLatencies(perf.samples[fun])
+		if len(latencies) == 0 {
+			continue
+		}
+
+		if !header {
+			fmt.Println("Function\tOperations\tp50 µs\tp90 µs\tp99 µs\tMax µs")
+			header = true
+		}
+
+		fmt.Printf("%s\t%d", fun, len(latencies))
+		for _, fraction := range []float64{0.5, 0.9, 0.99, 1} {
+			fmt.Printf("\t%.1f", float64(percentile(latencies, fraction).Nanoseconds())/1000)
+		}
+		fmt.Println()
+	}
+}
+
+// PrintBenchstat prints the measurements in the Go benchmark format, one line per run, to be processed by benchstat.
+// The config is appended to the benchmark name, e.g. "count=1000/size=64", and objectSize is used to compute MB/s.
+// See https://golang.org/design/14313-benchmark-format and https://godoc.org/golang.org/x/perf/cmd/benchstat
+func (perf *Executor) PrintBenchstat(functions []string, config string, objectSize int) {
+	if len(functions) == 0 {
+		for fun := range perf.samples {
+			functions = append(functions, fun)
+		}
+	}
+
+	var procs = runtime.GOMAXPROCS(0)
+	for _, fun := range functions {
+		for _, s := range perf.samples[fun] {
+			// each run is reported as a single iteration, per-object values are added as a custom unit
+			fmt.Printf("Benchmark%s/%s-%d\t1\t%d ns/op\t%d B/op\t%d allocs/op", fun, config, procs,
+				s.duration.Nanoseconds(), s.bytes, s.mallocs)
+			if s.objects > 0 {
+				if objectSize > 0 {
+					fmt.Printf("\t%.2f MB/s", float64(s.objects*objectSize)/1e6/s.duration.Seconds())
+				}
+				fmt.Printf("\t%.2f ns/object", float64(s.duration.Nanoseconds())/float64(s.objects))
+			}
+			if len(s.latencies) > 0 {
+				var latencies = sortedLatencies([]sample{s})
+				fmt.Printf("\t%d p50-ns/object\t%d p99-ns/object", percentile(latencies, 0.5).Nanoseconds(),
+					percentile(latencies, 0.99).Nanoseconds())
+			}
+			for _, unit := range metricUnits([]sample{s}) {
+				fmt.Printf("\t%.2f %s", s.metrics[unit], unit)
+			}
+			fmt.Println()
+		}
+	}
 }
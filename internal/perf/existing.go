@@ -0,0 +1,124 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"strings"
+)
+
+// runExisting executes the read-only tests against the database found in the directory and prints the results.
+// An empty database is populated first, so that it can be kept (see Options.KeepData) and reused by the next runs.
+func (perf *Executor) runExisting(options Options, model string) {
+	count, err := perf.exec.Count()
+	assert(err)
+
+	if count == 0 {
+		log.Printf("populating the empty database with %d objects", options.Count)
+		gen, err := NewGenerator(options)
+		assert(err)
+		count = uint64(perf.PutChunks(gen, options.Count, options.ChunkSize).count)
+	} else {
+		log.Printf("using the existing database with %d objects", count)
+	}
+
+	var rnd = rand.New(rand.NewSource(options.Seed))
+	var objectSize int
+	var size uint64
+
+	for i := 0; i < options.Runs; i++ {
+		perf.OpenExisting()
+
+		// the objects read are used to compute the expected results of the following reads & queries
+		var items = perf.ReadAll(int(count))
+		objectSize = averageObjectSize(items)
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		if len(items) == 0 {
+			log.Printf("the database is empty, skipping the reads, queries & aggregates")
+		} else {
+			perf.runGetMany(items, options, rnd)
+
+			// the query parameters are derived from the strings of the generated objects, see runQueries()
+			if sample := items[len(items)/2]; !strings.HasPrefix(sample.String, "Entity no. ") {
+				log.Printf("the objects weren't generated by this test (e.g. String %q), skipping the queries",
+					sample.String)
+			} else {
+				perf.runQueries(items)
+			}
+
+			perf.runAggregates(items)
+		}
+
+		log.Printf("%d/%d existing database finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions = []string{
+		"Init",
+		"PutChunks",
+		"OpenExisting",
+		"ReadAll",
+	}
+
+	for _, size := range options.GetManySizes {
+		functions = append(functions, getManyFunction(size, options.GetManySelection))
+	}
+
+	functions = append(functions,
+		"QueryStringPrefixCaseInsensitive",
+		"QueryStringContains",
+		"QueryInt32Equal",
+		"QueryInt64Greater",
+		"QueryFloat64Between",
+		"QueryInt32EqualAndInt64Greater",
+		"QueryInt32EqualOrFloat64Between",
+		"Count",
+		"CountInt64Greater",
+		"AggregateInt64",
+		"AggregateInt64Greater",
+		"AggregateFloat64",
+		"AggregateFloat64Between",
+	)
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("Existing database: model: %s, objects: %d, average object size: %d bytes", model, count,
+			objectSize),
+		fmt.Sprintf("model=%s/existing=%d/size=%d", model, count, objectSize),
+		objectSize, size)
+}
+
+// OpenExisting closes the database and measures opening it again with all its data
+func (perf *Executor) OpenExisting() {
+	assert(perf.exec.Release())
+
+	defer perf.trackTime(perf.start(0))
+	assert(perf.exec.Open())
+}
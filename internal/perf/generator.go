@@ -0,0 +1,208 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+// distributions of the generated values
+const (
+	DistributionSequential = "sequential"
+	DistributionUniform    = "uniform"
+	DistributionNormal     = "normal"
+	DistributionZipfian    = "zipfian"
+)
+
+// distribution returns a value in range [0, n) where n is given when creating the distribution.
+// The argument `i` is the sequence number of the generated object.
+type distribution func(i int) int64
+
+func newDistribution(name string, rnd *rand.Rand, n int64) (distribution, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	switch name {
+	case DistributionSequential:
+		return func(i int) int64 {
+			return int64(i) % n
+		}, nil
+
+	case DistributionUniform:
+		return func(int) int64 {
+			return rnd.Int63n(n)
+		}, nil
+
+	case DistributionNormal:
+		// centered in the middle of the range, 3 standard deviations on each side, clamped to the range bounds
+		var mean = float64(n-1) / 2
+		var stdDev = float64(n) / 6
+		return func(int) int64 {
+			var value = math.Round(rnd.NormFloat64()*stdDev + mean)
+			return int64(math.Max(0, math.Min(float64(n-1), value)))
+		}, nil
+
+	case DistributionZipfian:
+		var zipf = rand.NewZipf(rnd, 1.1, 1, uint64(n-1))
+		return func(int) int64 {
+			return int64(zipf.Uint64())
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown distribution %q", name)
+}
+
+// Generator produces test data, always the same for the same options (and seed in particular).
+type Generator struct {
+	rand            *rand.Rand
+	values          distribution // used for all numeric fields and the number in the string
+	stringLengths   distribution // added to stringMinLength
+	stringMinLength int
+	bytesLengths    distribution // added to bytesMinLength
+	bytesMinLength  int
+	count           int // number of objects generated so far
+}
+
+func NewGenerator(options Options) (*Generator, error) {
+	if options.StringMinLength > options.StringMaxLength {
+		return nil, fmt.Errorf("invalid string length range %d..%d", options.StringMinLength, options.StringMaxLength)
+	} else if options.BytesMinLength > options.BytesMaxLength {
+		return nil, fmt.Errorf("invalid byte-slice length range %d..%d", options.BytesMinLength, options.BytesMaxLength)
+	}
+
+	var gen = &Generator{
+		rand:            rand.New(rand.NewSource(options.Seed)),
+		stringMinLength: options.StringMinLength,
+		bytesMinLength:  options.BytesMinLength,
+	}
+
+	var err error
+	if gen.values, err = newDistribution(options.Distribution, gen.rand, int64(options.Count)); err != nil {
+		return nil, err
+	}
+
+	if gen.stringLengths, err = newDistribution(options.StringDistribution, gen.rand,
+		int64(options.StringMaxLength-options.StringMinLength+1)); err != nil {
+		return nil, err
+	}
+
+	if gen.bytesLengths, err = newDistribution(options.StringDistribution, gen.rand,
+		int64(options.BytesMaxLength-options.BytesMinLength+1)); err != nil {
+		return nil, err
+	}
+
+	return gen, nil
+}
+
+// Entity generates a new object; the ID is left empty.
+func (gen *Generator) Entity() *models.Entity {
+	var i = gen.count
+	gen.count++
+
+	var number = gen.values(i)
+	return &models.Entity{
+		String:  gen.string(number, gen.stringMinLength+int(gen.stringLengths(i))),
+		Float64: float64(gen.values(i)),
+		Int32:   int32(gen.values(i)),
+		Int64:   gen.values(i),
+		Bytes:   gen.bytes(gen.bytesMinLength + int(gen.bytesLengths(i))),
+	}
+}
+
+// Entities generates the given number of objects.
+func (gen *Generator) Entities(count int) []*models.Entity {
+	var result = make([]*models.Entity, count)
+	for i := 0; i < count; i++ {
+		result[i] = gen.Entity()
+	}
+	return result
+}
+
+// Customers generates the given number of customers, each with the given number of orders; IDs are left empty.
+func (gen *Generator) Customers(count, ordersPerCustomer int) []*models.Customer {
+	var result = make([]*models.Customer, count)
+	for i := 0; i < count; i++ {
+		var customer = &models.Customer{
+			Name:   "Customer no. " + strconv.FormatInt(gen.values(i), 10),
+			Orders: make([]*models.Order, ordersPerCustomer),
+		}
+
+		for j := 0; j < ordersPerCustomer; j++ {
+			customer.Orders[j] = &models.Order{
+				Date:   gen.values(i*ordersPerCustomer + j),
+				Amount: float64(gen.values(i*ordersPerCustomer+j)) / 100,
+			}
+		}
+
+		result[i] = customer
+	}
+	return result
+}
+
+// string produces "Entity no. <number>" padded by random lowercase letters to the given length.
+// The result may be longer than the requested length in case the number doesn't fit.
+func (gen *Generator) string(number int64, length int) string {
+	var bytes = make([]byte, 0, length)
+	bytes = append(bytes, "Entity no. "...)
+	bytes = strconv.AppendInt(bytes, number, 10)
+
+	if len(bytes) < length {
+		bytes = append(bytes, ' ')
+	}
+
+	for len(bytes) < length {
+		bytes = append(bytes, byte('a'+gen.rand.Intn(26)))
+	}
+
+	return string(bytes)
+}
+
+// bytes produces a random byte-slice payload of the given length, nil if the length is zero.
+func (gen *Generator) bytes(length int) []byte {
+	if length == 0 {
+		return nil
+	}
+
+	var bytes = make([]byte, length)
+	gen.rand.Read(bytes)
+	return bytes
+}
+
+// objectSize returns the approximate size of the object's data, i.e. the size of its fields' values.
+func objectSize(object *models.Entity) int {
+	// Id + Int32 + Int64 + Float64
+	const fixedSize = 8 + 4 + 8 + 8
+	return fixedSize + len(object.String) + len(object.Bytes)
+}
+
+// averageObjectSize returns the average approximate size of the given objects.
+func averageObjectSize(items []*models.Entity) int {
+	if len(items) == 0 {
+		return 0
+	}
+
+	var sum = 0
+	for _, object := range items {
+		sum += objectSize(object)
+	}
+	return sum / len(items)
+}
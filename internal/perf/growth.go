@@ -0,0 +1,201 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// growthFunction is the name of the growth profile measurements, formatted with the number of objects after the step
+const growthFunction = "Growth/objects=%d"
+
+// directorySize returns the total size of all files in the directory, e.g. including write-ahead logs and lock files
+func directorySize(path string) (uint64, error) {
+	var size uint64
+	var err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
+
+// writtenBytes returns the number of bytes this process has passed to write system calls so far, including the
+// background threads of the database (e.g. LSM tree compactions). It's only available on Linux (wchar in
+// /proc/self/io), otherwise false is returned. Writes through memory maps aren't included.
+func writtenBytes() (uint64, bool) {
+	file, err := os.Open("/proc/self/io")
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "wchar:") {
+			value, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "wchar:")), 10, 64)
+			return value, err == nil
+		}
+	}
+	return 0, false
+}
+
+// how settleWrites() detects that the background writes have finished: the written bytes must stay the same for
+// growthSettlePolls consecutive polls
+const (
+	growthSettleInterval = 100 * time.Millisecond
+	growthSettlePolls    = 5
+	growthSettleTimeout  = 30 * time.Second
+)
+
+// settleWrites waits until the process stops writing, i.e. until the background work of the database triggered by
+// the previous writes (e.g. LSM tree compactions) is finished, and returns the number of bytes written so far.
+// Gives up after growthSettleTimeout, e.g. if the database writes periodically.
+func settleWrites() (uint64, bool) {
+	written, ok := writtenBytes()
+	if !ok {
+		return 0, false
+	}
+
+	var deadline = time.Now().Add(growthSettleTimeout)
+	for stable := 0; stable < growthSettlePolls; {
+		if time.Now().After(deadline) {
+			log.Printf("the database is still writing after %v, not waiting for it anymore", growthSettleTimeout)
+			break
+		}
+
+		time.Sleep(growthSettleInterval)
+		if current, _ := writtenBytes(); current == written {
+			stable++
+		} else {
+			stable = 0
+			written = current
+		}
+	}
+	return written, true
+}
+
+// growthSteps returns the number of objects inserted by each of the steps
+func growthSteps(count, steps int) []int {
+	var sizes = make([]int, steps)
+	for i := range sizes {
+		// spread the remainder over the first steps
+		sizes[i] = count / steps
+		if i < count%steps {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
+
+// runGrowth inserts the objects in steps, starting with a new database in each run, and prints the database size,
+// bytes per object, write amplification and insert throughput after each step
+func (perf *Executor) runGrowth(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var inserts = perf.PrepareData(gen, options.Count)
+	var steps = growthSteps(len(inserts), options.GrowthSteps)
+	var size uint64
+
+	for i := 0; i < options.Runs; i++ {
+		// start from scratch, the previous objects would have left the database files grown
+		assert(perf.exec.Release())
+		assert(perf.exec.Init())
+		assert(perf.exec.SetModel(model))
+
+		removeIds(inserts)
+
+		var offset = 0
+		for _, step := range steps {
+			perf.GrowthStep(inserts[offset:offset+step], offset+step, options.BatchSize, options.Path)
+			offset += step
+		}
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		perf.RemoveAll(len(inserts))
+
+		log.Printf("%d/%d growth profile finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions []string
+	var total = 0
+	for _, step := range steps {
+		total += step
+		functions = append(functions, fmt.Sprintf(growthFunction, total))
+	}
+
+	var objectSize = averageObjectSize(inserts)
+	perf.printResults(options, functions,
+		fmt.Sprintf("Growth profile: model: %s, objects: %d, steps: %d, average object size: %d bytes", model,
+			options.Count, len(steps), objectSize),
+		fmt.Sprintf("model=%s/count=%d/size=%d", model, options.Count, objectSize),
+		objectSize, size)
+}
+
+// GrowthStep inserts the objects and records the database directory size and bytes per object afterwards, given the
+// total number of objects in the database, as well as the write amplification of the step if it can be measured
+func (perf *Executor) GrowthStep(items []*models.Entity, total, batchSize int, path string) {
+	var fun = fmt.Sprintf(growthFunction, total)
+	var writtenBefore, canMeasureWrites = writtenBytes()
+	var start = perf.start(len(items))
+	perf.putBatches(items, batchSize)
+	perf.trackTimeAs(fun, start)
+
+	// compactions run in the background, wait for them so that their writes are attributed to this step
+	if writtenAfter, ok := settleWrites(); ok && canMeasureWrites {
+		// write amplification: bytes written by the database per byte of the objects' data
+		var dataSize = 0
+		for _, object := range items {
+			dataSize += objectSize(object)
+		}
+		perf.setMetric(fun, "written-bytes", float64(writtenAfter-writtenBefore))
+		perf.setMetric(fun, "write-amplification", float64(writtenAfter-writtenBefore)/float64(dataSize))
+	}
+
+	var samples = perf.samples[fun]
+	var last = samples[len(samples)-1]
+	perf.setMetric(fun, "objects/s", float64(last.objects)/last.duration.Seconds())
+
+	size, err := directorySize(path)
+	assert(err)
+	perf.setMetric(fun, "bytes", float64(size))
+	perf.setMetric(fun, "bytes/object", float64(size)/float64(total))
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrowthSteps(t *testing.T) {
+	var tests = []struct {
+		count    int
+		steps    int
+		expected []int
+	}{
+		{10, 1, []int{10}},
+		{10, 2, []int{5, 5}},
+		{10, 3, []int{4, 3, 3}},
+		{10, 4, []int{3, 3, 2, 2}},
+		{10, 10, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{1000000, 7, []int{142858, 142857, 142857, 142857, 142857, 142857, 142857}},
+		{0, 0, []int{}},
+	}
+
+	for _, test := range tests {
+		var sizes = growthSteps(test.count, test.steps)
+		if !reflect.DeepEqual(sizes, test.expected) {
+			t.Errorf("%d objects in %d steps: got %v instead of %v", test.count, test.steps, sizes, test.expected)
+		}
+
+		var total = 0
+		for _, size := range sizes {
+			total += size
+		}
+		if total != test.count {
+			t.Errorf("%d objects in %d steps: the steps insert %d objects", test.count, test.steps, total)
+		}
+	}
+}
@@ -16,12 +16,87 @@
 
 package perf
 
+import (
+	"runtime"
+	"time"
+)
+
+// output formats of the results
+const (
+	FormatTable     = "table"
+	FormatBenchstat = "benchstat"
+)
+
+// selection of IDs to look up
+const (
+	SelectionRandom     = "random"
+	SelectionSequential = "sequential"
+)
+
+// durability levels, mapped by each database to its native settings
+const (
+	DurabilityFull   = "full"   // each commit is synced to the disk before it returns
+	DurabilityNormal = "normal" // committed data survives a crash of the process; a power loss may lose recent commits
+	DurabilityNone   = "none"   // no syncing at all; a crash may lose or corrupt data
+)
+
 type Options struct {
 	Path     string
 	Count    int
 	Runs     int
 	ManualGc bool
 	Profile  bool
+	Format   string
+
+	// test data generation
+	Seed               int64
+	Distribution       string // distribution of numeric values
+	StringDistribution string // distribution of string and byte-slice lengths
+	StringMinLength    int
+	StringMaxLength    int
+	BytesMinLength     int
+	BytesMaxLength     int
+	BytesSweep         []int // byte-slice lengths to run the tests with, overriding BytesMinLength/BytesMaxLength
+
+	Models []string // entity models to run the tests with, see ModelPlain and ModelIndexed
+
+	OrdersPerCustomer int // relations test: Count orders are split among Count/OrdersPerCustomer customers
+
+	PageSize int // number of objects per page when paging through ordered query results
+
+	SingleOps int // number of objects to put, get & remove one by one, each in its own transaction
+
+	GetManySizes     []int  // numbers of IDs looked up at once
+	GetManySelection string // how the looked-up IDs are selected, see SelectionRandom and SelectionSequential
+
+	Readers            int           // concurrent readers test: maximum number of reader goroutines, 0 to skip the test
+	ConcurrentDuration time.Duration // duration of each phase of the concurrent tests
+
+	MixedWorkers int     // mixed read/write workload test: number of goroutines, 0 to skip the test
+	ReadRatio    float64 // mixed read/write workload test: fraction of the operations which are reads
+
+	YcsbWorkloads  []string // YCSB core workloads to run (A to F), none to skip the YCSB test
+	YcsbOperations int      // number of operations of the run phase of each YCSB workload
+	YcsbThreads    int      // number of goroutines executing the run phase of the YCSB workloads
+
+	ScaleCount int // large dataset mode: number of objects, replacing all the other tests; 0 to run the other tests
+	ChunkSize  int // large dataset mode: number of objects generated, inserted and read at once
+
+	BatchSize  int   // number of objects inserted in a single transaction by PutBulk, 0 to insert all at once
+	BatchSweep []int // batch size sweep test: batch sizes to insert the objects with, none to skip the test
+
+	Durability string // see DurabilityFull, DurabilityNormal and DurabilityNone
+
+	BackendOptions map[string]string // database specific open options, passed as-is to the tested database
+
+	CrashIterations int           // crash consistency test: number of times the writing child process is killed
+	CrashMaxDelay   time.Duration // crash consistency test: maximum time after which the child process is killed
+	CrashChild      bool          // set internally for the child process of the crash consistency test
+
+	KeepData bool // whether to keep the database directory after the run instead of removing it
+	Existing bool // existing database mode: the database isn't removed on start, replacing all the other tests
+
+	GrowthSteps int // growth profile test: number of steps inserting the objects, 0 to skip the test
 }
 
 var OptionsDefaults = Options{
@@ -31,4 +106,38 @@ var OptionsDefaults = Options{
 	10,
 	false,
 	false,
+	FormatTable,
+	1,
+	DistributionUniform,
+	DistributionUniform,
+	16,
+	32,
+	0,
+	0,
+	nil,
+	[]string{ModelPlain},
+	0,
+	100,
+	1000,
+	[]int{1, 10, 100, 1000},
+	SelectionRandom,
+	runtime.GOMAXPROCS(0),
+	time.Second,
+	0,
+	0.9,
+	nil,
+	10000,
+	1,
+	0,
+	10000,
+	0,
+	nil,
+	DurabilityFull,
+	nil,
+	0,
+	time.Second,
+	false,
+	false,
+	false,
+	0,
 }
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"hash/fnv"
+	"log"
+	"math"
+	"runtime"
+)
+
+// scaleInt32Candidates is the number of the first objects whose Int32 values are candidates for QueryInt32Equal
+const scaleInt32Candidates = 16
+
+// scaleMatches describes the objects matching a query of the large dataset test without keeping them in memory
+type scaleMatches struct {
+	count    int
+	checksum uint64 // sum of entityChecksum() of the objects
+}
+
+func (matches *scaleMatches) add(object *models.Entity) {
+	matches.count++
+	matches.checksum += entityChecksum(object)
+}
+
+// scaleExpectations are collected while inserting the large dataset, to check the results of reads and queries
+// without keeping the objects in memory
+type scaleExpectations struct {
+	count      int
+	totalSize  int             // sum of objectSize() of all objects
+	checksum   uint64          // sum of entityChecksum() of all objects
+	aggregates Int64Aggregates // of the Int64 property of all objects
+
+	// the least frequent of these values is queried, so that the results of a skewed distribution (e.g. zipfian,
+	// where a single value can match a large share of the objects) don't need to fit in memory either
+	int32Candidates []int32        // distinct values of the first objects
+	int32Matches    []scaleMatches // objects with Int32 equal to each of int32Candidates
+
+	int64Value   int64        // selects (roughly) the top 0.1 % of the values
+	int64Matches scaleMatches // objects with Int64 greater than int64Value
+
+	middle      int // index of the first of the 100 objects in the middle of the dataset
+	middleMinId uint64
+	middleMaxId uint64
+	middleCount int
+}
+
+func newScaleExpectations(count int) *scaleExpectations {
+	var middle = count/2 - 50
+	if middle < 0 {
+		middle = 0
+	}
+
+	return &scaleExpectations{
+		aggregates: Int64Aggregates{Min: math.MaxInt64, Max: math.MinInt64},
+		int64Value: int64(float64(count) * 0.999),
+		middle:     middle,
+	}
+}
+
+// include adds the inserted object at the given position in the dataset to the expectations
+func (expected *scaleExpectations) include(index int, object *models.Entity) {
+	if index < scaleInt32Candidates && !containsInt32(expected.int32Candidates, object.Int32) {
+		expected.int32Candidates = append(expected.int32Candidates, object.Int32)
+		expected.int32Matches = append(expected.int32Matches, scaleMatches{})
+	}
+
+	expected.count++
+	expected.totalSize += objectSize(object)
+	expected.checksum += entityChecksum(object)
+
+	expected.aggregates.Count++
+	expected.aggregates.Sum += object.Int64
+	if object.Int64 < expected.aggregates.Min {
+		expected.aggregates.Min = object.Int64
+	}
+	if object.Int64 > expected.aggregates.Max {
+		expected.aggregates.Max = object.Int64
+	}
+
+	for i, value := range expected.int32Candidates {
+		if object.Int32 == value {
+			expected.int32Matches[i].add(object)
+		}
+	}
+	if object.Int64 > expected.int64Value {
+		expected.int64Matches.add(object)
+	}
+
+	// IDs are assigned in ascending order so the range of these IDs contains only these objects
+	if index >= expected.middle && index < expected.middle+100 {
+		if expected.middleCount == 0 {
+			expected.middleMinId = object.Id
+		}
+		expected.middleMaxId = object.Id
+		expected.middleCount++
+	}
+}
+
+// int32Query returns the least frequent of the candidate Int32 values and the objects matching it
+func (expected *scaleExpectations) int32Query() (int32, scaleMatches) {
+	var best = 0
+	for i := range expected.int32Matches {
+		if expected.int32Matches[i].count < expected.int32Matches[best].count {
+			best = i
+		}
+	}
+	return expected.int32Candidates[best], expected.int32Matches[best]
+}
+
+func containsInt32(values []int32, value int32) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// entityChecksum returns a hash of all fields of the object
+func entityChecksum(object *models.Entity) uint64 {
+	var hash = fnv.New64a()
+	var buffer [8]byte
+	var writeUint64 = func(value uint64) {
+		binary.LittleEndian.PutUint64(buffer[:], value)
+		_, _ = hash.Write(buffer[:])
+	}
+
+	writeUint64(object.Id)
+	writeUint64(uint64(object.Int32))
+	writeUint64(uint64(object.Int64))
+	writeUint64(math.Float64bits(object.Float64))
+	_, _ = hash.Write([]byte(object.String))
+	_, _ = hash.Write(object.Bytes)
+	return hash.Sum64()
+}
+
+// runScale executes the large dataset test suite and prints the results. The objects are generated, inserted and read
+// in chunks so that the dataset doesn't have to fit in memory.
+func (perf *Executor) runScale(options Options, model string) {
+	// the values are generated from the range given by the number of objects
+	var scaleOptions = options
+	scaleOptions.Count = options.ScaleCount
+
+	var expected *scaleExpectations
+	var size uint64
+
+	for i := 0; i < options.Runs; i++ {
+		gen, err := NewGenerator(scaleOptions)
+		assert(err)
+
+		expected = perf.PutChunks(gen, options.ScaleCount, options.ChunkSize)
+
+		if size_, err := perf.exec.Size(); err != nil {
+			panic(err)
+		} else {
+			size = size_
+		}
+
+		perf.ReadChunks(options.ChunkSize, expected)
+		perf.Count(uint64(expected.count))
+		perf.Query100IdsBetween(expected.middleMinId, expected.middleMaxId, expected.middleCount)
+		var int32Value, int32Matches = expected.int32Query()
+		perf.queryMatches("QueryInt32Equal", int32Matches, func() ([]*models.Entity, error) {
+			return perf.exec.QueryInt32Equal(int32Value)
+		})
+		perf.queryMatches("QueryInt64Greater", expected.int64Matches, func() ([]*models.Entity, error) {
+			return perf.exec.QueryInt64Greater(expected.int64Value)
+		})
+		perf.CountInt64Greater(expected.int64Value, uint64(expected.int64Matches.count))
+		perf.AggregateInt64(expected.aggregates)
+		perf.RemoveAll(expected.count)
+
+		log.Printf("%d/%d large dataset finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	var functions = []string{
+		"PutChunks",
+		"ReadChunks",
+		"Count",
+		"Query100IdsBetween",
+		"QueryInt32Equal",
+		"QueryInt64Greater",
+		"CountInt64Greater",
+		"AggregateInt64",
+		"RemoveAll",
+	}
+
+	var objectSize = 0
+	if expected.count > 0 {
+		objectSize = expected.totalSize / expected.count
+	}
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("Large dataset: model: %s, objects: %d, chunk size: %d, average object size: %d bytes", model,
+			options.ScaleCount, options.ChunkSize, objectSize),
+		fmt.Sprintf("model=%s/count=%d/chunk=%d/size=%d", model, options.ScaleCount, options.ChunkSize, objectSize),
+		objectSize, size)
+}
+
+// PutChunks generates and inserts the given number of objects in chunks, each in its own transaction, and returns
+// the expected results of the reads & queries. Generating the objects is not included in the measurement.
+func (perf *Executor) PutChunks(gen *Generator, count, chunkSize int) *scaleExpectations {
+	var expected = newScaleExpectations(count)
+	var chunks = 0
+
+	for offset := 0; offset < count; offset += chunkSize {
+		var size = chunkSize
+		if offset+size > count {
+			size = count - offset
+		}
+
+		var items = gen.Entities(size)
+
+		var start = perf.start(len(items))
+		assert(perf.exec.PutBulk(items))
+		perf.trackTimeAs("PutChunks", start)
+		chunks++
+
+		for i, object := range items {
+			expected.include(offset+i, object)
+		}
+	}
+
+	perf.mergeSamples("PutChunks", chunks)
+
+	if expected.count > 0 {
+		expected.aggregates.Average = float64(expected.aggregates.Sum) / float64(expected.aggregates.Count)
+	}
+	return expected
+}
+
+// ReadChunks reads all objects in chunks ordered by ID, checking them against the checksum of the inserted ones
+func (perf *Executor) ReadChunks(chunkSize int, expected *scaleExpectations) {
+	defer perf.trackTime(perf.start(expected.count))
+
+	var count = 0
+	var checksum uint64
+	var lastId uint64
+	for {
+		items, err := perf.exec.ReadChunk(lastId, chunkSize)
+		if err != nil {
+			panic(err)
+		} else if len(items) == 0 {
+			break
+		} else if len(items) > chunkSize {
+			panic(fmt.Errorf("invalid number of objects read in a chunk - %d, limit %d", len(items), chunkSize))
+		}
+
+		for _, object := range items {
+			if object.Id <= lastId {
+				panic(fmt.Errorf("objects read are not ordered by ID: %d after %d", object.Id, lastId))
+			}
+			lastId = object.Id
+			checksum += entityChecksum(object)
+		}
+		count += len(items)
+	}
+
+	if count != expected.count {
+		panic(fmt.Errorf("invalid number of objects read - %d instead of %d", count, expected.count))
+	} else if checksum != expected.checksum {
+		panic(fmt.Errorf("objects read differ from the ones written"))
+	}
+}
+
+// queryMatches executes the query measured as the given function, checking the results against the count & checksum
+// of the expected objects, instead of the IDs which the other tests keep in memory
+func (perf *Executor) queryMatches(fun string, expected scaleMatches, query func() ([]*models.Entity, error)) {
+	defer perf.trackTimeAs(fun, perf.start(expected.count))
+
+	items, err := query()
+	if err != nil {
+		panic(err)
+	} else if len(items) != expected.count {
+		panic(fmt.Errorf("invalid number of objects returned by %s - %d instead of %d", fun, len(items),
+			expected.count))
+	}
+
+	var checksum uint64
+	for _, object := range items {
+		checksum += entityChecksum(object)
+	}
+	if checksum != expected.checksum {
+		panic(fmt.Errorf("objects returned by %s differ from the expected ones", fun))
+	}
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package perf
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"hash/fnv"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// The YCSB core workloads, see https://github.com/brianfrankcooper/YCSB/wiki/Core-Workloads
+// Records are the test objects, keys are their IDs; fields are not read or written separately.
+
+// operation types of the YCSB workloads
+const (
+	ycsbRead = iota
+	ycsbUpdate
+	ycsbInsert
+	ycsbScan
+	ycsbReadModifyWrite
+)
+
+// ycsbOperations are the names of the YCSB operation types, indexed by the type
+var ycsbOperations = []string{"read", "update", "insert", "scan", "read-modify-write"}
+
+// request distributions of the YCSB workloads
+const (
+	ycsbZipfian = "zipfian" // popular records are spread across the key space
+	ycsbLatest  = "latest"  // the most recently inserted records are the most popular
+)
+
+// ycsbWorkload defines the proportions of the operation types, indexed by the type, and how records are chosen
+type ycsbWorkload struct {
+	proportions  [5]float64
+	distribution string
+}
+
+var ycsbWorkloads = map[string]ycsbWorkload{
+	"A": {[5]float64{ycsbRead: 0.5, ycsbUpdate: 0.5}, ycsbZipfian},          // update heavy
+	"B": {[5]float64{ycsbRead: 0.95, ycsbUpdate: 0.05}, ycsbZipfian},        // read mostly
+	"C": {[5]float64{ycsbRead: 1}, ycsbZipfian},                             // read only
+	"D": {[5]float64{ycsbRead: 0.95, ycsbInsert: 0.05}, ycsbLatest},         // read latest
+	"E": {[5]float64{ycsbScan: 0.95, ycsbInsert: 0.05}, ycsbZipfian},        // short ranges
+	"F": {[5]float64{ycsbRead: 0.5, ycsbReadModifyWrite: 0.5}, ycsbZipfian}, // read-modify-write
+}
+
+// ycsbMaxScanLength is the maximum number of records read by a scan; the length is uniformly distributed
+const ycsbMaxScanLength = 100
+
+// operation chooses the operation type according to the workload proportions
+func (workload ycsbWorkload) operation(rnd *rand.Rand) int {
+	var r = rnd.Float64()
+	for operation, proportion := range workload.proportions {
+		if r < proportion {
+			return operation
+		}
+		r -= proportion
+	}
+	return ycsbRead // only reached due to rounding
+}
+
+// ycsbZipfianGenerator produces values in range [0, n) with the zipfian distribution, most popular being 0.
+// Unlike rand.Zipf, it supports the constant 0.99 used by YCSB, see "Quickly Generating Billion-Record Synthetic
+// Databases" by Jim Gray et al.
+type ycsbZipfianGenerator struct {
+	n     float64
+	theta float64
+	alpha float64
+	zetan float64
+	eta   float64
+}
+
+func newYcsbZipfianGenerator(n int) *ycsbZipfianGenerator {
+	const theta = 0.99
+	var zeta = func(n int) float64 {
+		var sum = 0.0
+		for i := 1; i <= n; i++ {
+			sum += 1 / math.Pow(float64(i), theta)
+		}
+		return sum
+	}
+
+	var gen = &ycsbZipfianGenerator{
+		n:     float64(n),
+		theta: theta,
+		alpha: 1 / (1 - theta),
+		zetan: zeta(n),
+	}
+	gen.eta = (1 - math.Pow(2/gen.n, 1-theta)) / (1 - zeta(2)/gen.zetan)
+	return gen
+}
+
+func (gen *ycsbZipfianGenerator) next(rnd *rand.Rand) int {
+	var u = rnd.Float64()
+	var uz = u * gen.zetan
+	if uz < 1 {
+		return 0
+	} else if uz < 1+math.Pow(0.5, gen.theta) {
+		return 1
+	}
+	return int(math.Min(gen.n-1, gen.n*math.Pow(gen.eta*u-gen.eta+1, gen.alpha)))
+}
+
+// ycsbKeys holds the IDs of the records, growing with inserts, shared by all the threads
+type ycsbKeys struct {
+	mutex   sync.RWMutex
+	ids     []uint64
+	zipfian *ycsbZipfianGenerator
+}
+
+func (keys *ycsbKeys) add(id uint64) {
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+	keys.ids = append(keys.ids, id)
+}
+
+// choose returns a record ID, chosen with the given distribution, and the number of records following it
+func (keys *ycsbKeys) choose(rnd *rand.Rand, distribution string) (uint64, int) {
+	keys.mutex.RLock()
+	defer keys.mutex.RUnlock()
+
+	var count = len(keys.ids)
+	var index int
+	if distribution == ycsbLatest {
+		index = count - 1 - keys.zipfian.next(rnd)
+		if index < 0 {
+			index = 0
+		}
+	} else {
+		// scrambled, i.e. the popular records are not clustered together
+		var hash = fnv.New64a()
+		var value = uint64(keys.zipfian.next(rnd))
+		var bytes [8]byte
+		for i := range bytes {
+			bytes[i] = byte(value >> (8 * uint(i)))
+		}
+		_, _ = hash.Write(bytes[:])
+		index = int(hash.Sum64() % uint64(count))
+	}
+	return keys.ids[index], count - 1 - index
+}
+
+// ycsbThread holds the state of a single goroutine of the YCSB run phase
+type ycsbThread struct {
+	operationStats
+	rnd *rand.Rand
+	gen *Generator
+}
+
+// execute runs a single operation of the workload, recording its latency or its error
+func (thread *ycsbThread) execute(exec Executable, workload ycsbWorkload, keys *ycsbKeys) {
+	var operation = workload.operation(thread.rnd)
+	var id, following = keys.choose(thread.rnd, workload.distribution)
+
+	var start = time.Now()
+	var err error
+	switch operation {
+	case ycsbRead:
+		if item, err2 := exec.Get(id); err2 != nil {
+			err = err2
+		} else if item == nil {
+			err = fmt.Errorf("record %d not found", id)
+		}
+
+	case ycsbUpdate:
+		var object = thread.gen.Entity()
+		object.Id = id
+		err = exec.Put(object)
+
+	case ycsbInsert:
+		var object = thread.gen.Entity()
+		if err = exec.Put(object); err == nil {
+			keys.add(object.Id)
+		}
+
+	case ycsbScan:
+		// records are scanned in the order of their IDs, which are assigned sequentially
+		var length = 1 + thread.rnd.Intn(ycsbMaxScanLength)
+		if length > following+1 {
+			length = following + 1
+		}
+		if items, err2 := exec.QueryIdBetween(id, id+uint64(length)-1); err2 != nil {
+			err = err2
+		} else if len(items) == 0 {
+			err = fmt.Errorf("scan from record %d returned no records", id)
+		}
+
+	case ycsbReadModifyWrite:
+		if item, err2 := exec.Get(id); err2 != nil {
+			err = err2
+		} else if item == nil {
+			err = fmt.Errorf("record %d not found", id)
+		} else {
+			item.Int64++
+			err = exec.Put(item)
+		}
+	}
+
+	thread.record(operation, start, err)
+}
+
+// runYcsb executes the configured YCSB workloads, each with a load phase and a run phase, and prints the results
+func (perf *Executor) runYcsb(options Options, model string) {
+	gen, err := NewGenerator(options)
+	assert(err)
+
+	var records = perf.PrepareData(gen, options.Count)
+	var size uint64
+	var functions []string
+
+	for _, name := range options.YcsbWorkloads {
+		functions = append(functions, fmt.Sprintf(ycsbLoadFunction, name))
+		functions = append(functions, operationFunctions(fmt.Sprintf(ycsbRunFunction, name), ycsbOperations)...)
+	}
+
+	for i := 0; i < options.Runs; i++ {
+		for _, name := range options.YcsbWorkloads {
+			removeIds(records)
+			perf.YcsbLoad(name, records)
+
+			var keys = &ycsbKeys{
+				ids:     make([]uint64, len(records)),
+				zipfian: newYcsbZipfianGenerator(len(records)),
+			}
+			for k, object := range records {
+				keys.ids[k] = object.Id
+			}
+
+			// each thread generates different objects to insert & update
+			var threads = make([]*ycsbThread, options.YcsbThreads)
+			for t := range threads {
+				var threadOptions = options
+				threadOptions.Seed = options.Seed + int64(t) + 1
+				threadGen, err := NewGenerator(threadOptions)
+				assert(err)
+
+				threads[t] = &ycsbThread{
+					operationStats: newOperationStats(len(ycsbOperations)),
+					rnd:            rand.New(rand.NewSource(threadOptions.Seed)),
+					gen:            threadGen,
+				}
+			}
+
+			perf.YcsbRun(name, keys, threads, options.YcsbOperations)
+
+			if size_, err := perf.exec.Size(); err != nil {
+				panic(err)
+			} else {
+				size = size_
+			}
+
+			perf.RemoveAll(len(keys.ids))
+		}
+
+		log.Printf("%d/%d YCSB finished", i+1, options.Runs)
+
+		if options.ManualGc {
+			// manually invoke GC out of benchmarked time
+			runtime.GC()
+			log.Printf("%d/%d garbage-collector executed", i+1, options.Runs)
+		}
+	}
+
+	perf.printResults(options, functions,
+		fmt.Sprintf("YCSB: model: %s, records: %d, operations: %d, threads: %d", model, options.Count,
+			options.YcsbOperations, options.YcsbThreads),
+		fmt.Sprintf("model=%s/records=%d/threads=%d", model, options.Count, options.YcsbThreads),
+		averageObjectSize(records), size)
+}
+
+// names of the YCSB measurements, formatted with the workload name
+const (
+	ycsbLoadFunction = "YcsbLoad/workload=%s"
+	ycsbRunFunction  = "YcsbRun/workload=%s"
+)
+
+// YcsbLoad inserts the records of the workload, in a single transaction
+func (perf *Executor) YcsbLoad(workload string, records []*models.Entity) {
+	var fun = fmt.Sprintf(ycsbLoadFunction, workload)
+	var start = perf.start(len(records))
+	assert(perf.exec.PutBulk(records))
+	perf.trackTimeAs(fun, start)
+
+	var samples = perf.samples[fun]
+	var last = samples[len(samples)-1]
+	perf.setMetric(fun, "ops/s", float64(last.objects)/last.duration.Seconds())
+}
+
+// YcsbRun executes the given number of operations of the workload, split evenly among the threads
+func (perf *Executor) YcsbRun(workload string, keys *ycsbKeys, threads []*ycsbThread, operations int) {
+	var fun = fmt.Sprintf(ycsbRunFunction, workload)
+	var definition = ycsbWorkloads[workload]
+
+	var start = perf.start(operations)
+	var wg sync.WaitGroup
+	for t, thread := range threads {
+		var count = operations / len(threads)
+		if t < operations%len(threads) {
+			count++
+		}
+
+		wg.Add(1)
+		go func(thread *ycsbThread, count int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				thread.execute(perf.exec, definition, keys)
+			}
+		}(thread, count)
+	}
+	wg.Wait()
+	perf.trackTimeAs(fun, start)
+
+	var stats = make([]*operationStats, len(threads))
+	for i, thread := range threads {
+		stats[i] = &thread.operationStats
+	}
+	perf.trackOperations(fun, ycsbOperations, stats)
+}
@@ -0,0 +1,411 @@
+/*
+ * Copyright 2019 ObjectBox Ltd. All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/objectbox/objectbox-go-performance/internal/cmd"
+	"github.com/objectbox/objectbox-go-performance/internal/kv"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"github.com/objectbox/objectbox-go-performance/internal/perf"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+)
+
+func main() {
+	var options = cmd.GetOptions()
+
+	var executable = &LevelDbPerf{
+		path:           options.Path,
+		durability:     options.Durability,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
+	}
+
+	var executor = perf.CreateExecutor(executable)
+	defer executor.Close()
+
+	executor.Run(options)
+}
+
+// perf executable using goleveldb, an LSM tree, storing the objects using the binary encoding & the reads of the kv
+// package. LevelDB has a single key space so each table (i.e. the objects of a type and each index) has its own key
+// prefix. Writes are collected in a batch, which is written atomically. LevelDB has no transactions isolating the reads
+// of a read-modify-write (e.g. the previous index values of an update) so the writes are serialized by a mutex.
+type LevelDbPerf struct {
+	kv.Store
+	path           string
+	durability     string
+	backendOptions map[string]string // leveldb options, see levelDbOptions()
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	db             *leveldb.DB
+	writeOptions   *opt.WriteOptions
+	writeMutex     sync.Mutex        // serializes the writes, guards lastIds & asyncBatch
+	lastIds        map[string]uint64 // last ID assigned, by the table name
+	asyncBatch     *leveldb.Batch    // used for PutAsync
+}
+
+// tables with IDs assigned on insert
+var idTables = [][]byte{kv.PlainTables.Data, kv.IndexedTables.Data, kv.CustomerTable, kv.OrderTable}
+
+func (exec *LevelDbPerf) Init() error {
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(exec.path, 0777); err != nil {
+		return err
+	}
+
+	if err := exec.Open(); err != nil {
+		return err
+	}
+
+	exec.Store.View = exec.view
+
+	return exec.SetModel(perf.ModelPlain)
+}
+
+// levelDbOptions maps the durability level and the backend options to leveldb options
+func (exec *LevelDbPerf) levelDbOptions() (*opt.Options, *opt.WriteOptions, error) {
+	var options = &opt.Options{}
+	var writeOptions = &opt.WriteOptions{}
+
+	switch exec.durability {
+	case perf.DurabilityFull:
+		writeOptions.Sync = true
+	case perf.DurabilityNormal:
+		// the journal is written on each write but not synced
+	case perf.DurabilityNone:
+		// not even the table files and the manifest are synced
+		options.NoSync = true
+	default:
+		return nil, nil, fmt.Errorf("unknown durability level %s", exec.durability)
+	}
+
+	for key, value := range exec.backendOptions {
+		var err error
+		switch key {
+		case "write-buffer":
+			options.WriteBuffer, err = strconv.Atoi(value)
+		case "block-cache-capacity":
+			options.BlockCacheCapacity, err = strconv.Atoi(value)
+		case "block-size":
+			options.BlockSize, err = strconv.Atoi(value)
+		case "compaction-table-size":
+			options.CompactionTableSize, err = strconv.Atoi(value)
+		case "open-files-cache-capacity":
+			options.OpenFilesCacheCapacity, err = strconv.Atoi(value)
+		case "bloom-filter-bits":
+			var bits int
+			bits, err = strconv.Atoi(value)
+			options.Filter = filter.NewBloomFilter(bits)
+		case "compression":
+			switch value {
+			case "none":
+				options.Compression = opt.NoCompression
+			case "snappy":
+				options.Compression = opt.SnappyCompression
+			default:
+				err = fmt.Errorf("expected none or snappy")
+			}
+		default:
+			err = fmt.Errorf("unknown option, expected one of write-buffer, block-cache-capacity, block-size, " +
+				"compaction-table-size, open-files-cache-capacity, bloom-filter-bits, compression")
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid backend option %s=%s: %s", key, value, err)
+		}
+	}
+
+	return options, writeOptions, nil
+}
+
+// Open opens the database in the existing directory
+func (exec *LevelDbPerf) Open() error {
+	options, writeOptions, err := exec.levelDbOptions()
+	if err != nil {
+		return err
+	}
+
+	if db, err := leveldb.OpenFile(exec.path, options); err != nil {
+		return err
+	} else {
+		exec.db = db
+		exec.writeOptions = writeOptions
+	}
+
+	// leveldb has no sequences, continue after the last stored ID
+	exec.lastIds = make(map[string]uint64)
+	for _, table := range idTables {
+		var prefix = kv.KeyPrefix(table)
+		var it = exec.db.NewIterator(util.BytesPrefix(prefix), nil)
+		if it.Last() {
+			exec.lastIds[string(table)] = kv.IdFromKey(it.Key()[len(prefix):])
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (exec *LevelDbPerf) Release() error {
+	return exec.db.Close()
+}
+
+func (exec *LevelDbPerf) Close() error {
+	if err := exec.db.Close(); err != nil {
+		return err
+	}
+
+	if exec.keepData {
+		return nil
+	}
+
+	return os.RemoveAll(exec.path)
+}
+
+// Size returns the total size of the table files, the journal, the manifest and the log
+func (exec *LevelDbPerf) Size() (uint64, error) {
+	files, err := ioutil.ReadDir(exec.path)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, file := range files {
+		size += uint64(file.Size())
+	}
+	return size, nil
+}
+
+// batches
+
+// reader reads from the database or a snapshot, each table is a key prefix
+type reader struct {
+	db leveldb.Reader
+}
+
+func (r reader) Get(table, key []byte) ([]byte, error) {
+	if value, err := r.db.Get(kv.PrefixedKey(table, key), nil); err == leveldb.ErrNotFound {
+		return nil, nil
+	} else {
+		return value, err
+	}
+}
+
+func (r reader) Iterate(table, start []byte, reverse bool, fn func(key, value []byte) (bool, error)) error {
+	var prefix = kv.KeyPrefix(table)
+	var it = r.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer it.Release()
+
+	var valid bool
+	if reverse {
+		valid = it.Last()
+	} else if start != nil {
+		valid = it.Seek(append(prefix, start...))
+	} else {
+		valid = it.First()
+	}
+
+	for ; valid; valid = step(it, reverse) {
+		if next, err := fn(it.Key()[len(prefix):], it.Value()); err != nil {
+			return err
+		} else if !next {
+			break
+		}
+	}
+	return it.Error()
+}
+
+func (r reader) IterateKeys(table, start []byte, reverse bool, fn func(key []byte) (bool, error)) error {
+	return r.Iterate(table, start, reverse, func(key, _ []byte) (bool, error) {
+		return fn(key)
+	})
+}
+
+// step moves the iterator in the direction of the iteration
+func step(it iterator.Iterator, reverse bool) bool {
+	if reverse {
+		return it.Prev()
+	}
+	return it.Next()
+}
+
+// writer collects the writes in a batch, reading the current state of the database; IDs continue after the last
+// assigned one. Must only be used with the write mutex held.
+type writer struct {
+	reader
+	batch   *leveldb.Batch
+	lastIds map[string]uint64
+}
+
+func (w writer) Set(table, key, value []byte) error {
+	w.batch.Put(kv.PrefixedKey(table, key), value)
+	return nil
+}
+
+func (w writer) Delete(table, key []byte) error {
+	w.batch.Delete(kv.PrefixedKey(table, key))
+	return nil
+}
+
+func (w writer) NextId(table []byte) (uint64, error) {
+	w.lastIds[string(table)]++
+	return w.lastIds[string(table)], nil
+}
+
+// newWriter creates a writer collecting the writes in the given batch; must be called with the write mutex held
+func (exec *LevelDbPerf) newWriter(batch *leveldb.Batch) writer {
+	return writer{reader{exec.db}, batch, exec.lastIds}
+}
+
+// write collects the writes of the function in a batch and writes it atomically.
+// The previous values (e.g. of the indexes) are read from the database so an object must not be put twice in the
+// same batch.
+func (exec *LevelDbPerf) write(fn func(writer kv.Writer) error) error {
+	exec.writeMutex.Lock()
+	defer exec.writeMutex.Unlock()
+
+	var batch = new(leveldb.Batch)
+	if err := fn(exec.newWriter(batch)); err != nil {
+		return err
+	}
+	return exec.db.Write(batch, exec.writeOptions)
+}
+
+// view runs the function on a snapshot so that all its reads see the same state of the database
+func (exec *LevelDbPerf) view(fn func(reader kv.Reader) error) error {
+	snapshot, err := exec.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Release()
+
+	return fn(reader{snapshot})
+}
+
+// writing
+
+// removeTables deletes all keys of the tables; leveldb has no range deletion, the deletions are tombstones removed by
+// later compactions
+func (exec *LevelDbPerf) removeTables(tables [][]byte) error {
+	return exec.write(func(writer kv.Writer) error {
+		for _, table := range tables {
+			if err := writer.IterateKeys(table, nil, false, func(key []byte) (bool, error) {
+				return true, writer.Delete(table, key)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *LevelDbPerf) RemoveAll() error {
+	return exec.removeTables(exec.Tables.All())
+}
+
+func (exec *LevelDbPerf) RemoveBulk(items []*models.Entity) error {
+	return exec.write(func(writer kv.Writer) error {
+		for _, object := range items {
+			if err := kv.Remove(writer, exec.Tables, object.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *LevelDbPerf) PutAsync(item *models.Entity) error {
+	exec.writeMutex.Lock()
+	defer exec.writeMutex.Unlock()
+
+	// PutAsync is simulated by collecting a batch and writing it afterwards
+	if exec.asyncBatch == nil {
+		exec.asyncBatch = new(leveldb.Batch)
+	}
+
+	if err := kv.Put(exec.newWriter(exec.asyncBatch), exec.Tables, item); err != nil {
+		// the batch may contain a part of the failed put, it can't be written
+		var lost = exec.asyncBatch.Len()
+		exec.asyncBatch = nil
+		return fmt.Errorf("%s; the batch of %d pending asynchronous writes was discarded", err, lost)
+	}
+	return nil
+}
+
+func (exec *LevelDbPerf) AwaitAsyncCompletion() error {
+	exec.writeMutex.Lock()
+	defer exec.writeMutex.Unlock()
+
+	if exec.asyncBatch != nil {
+		var err = exec.db.Write(exec.asyncBatch, exec.writeOptions)
+		exec.asyncBatch = nil
+		return err
+	}
+
+	return nil
+}
+
+func (exec *LevelDbPerf) PutBulk(items []*models.Entity) error {
+	return exec.write(func(writer kv.Writer) error {
+		for _, item := range items {
+			if err := kv.Put(writer, exec.Tables, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (exec *LevelDbPerf) Put(item *models.Entity) error {
+	return exec.write(func(writer kv.Writer) error {
+		return kv.Put(writer, exec.Tables, item)
+	})
+}
+
+func (exec *LevelDbPerf) Remove(item *models.Entity) error {
+	return exec.write(func(writer kv.Writer) error {
+		return kv.Remove(writer, exec.Tables, item.Id)
+	})
+}
+
+// relations
+
+func (exec *LevelDbPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.write(func(writer kv.Writer) error {
+		return kv.PutCustomers(writer, customers)
+	})
+}
+
+func (exec *LevelDbPerf) RemoveAllCustomers() error {
+	return exec.removeTables(kv.RelationTables)
+}
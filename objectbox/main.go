@@ -18,6 +18,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/google/flatbuffers/go"
 	"github.com/objectbox/objectbox-go-performance/internal/cmd"
 	"github.com/objectbox/objectbox-go-performance/internal/models"
 	"github.com/objectbox/objectbox-go-performance/internal/perf"
@@ -25,13 +26,17 @@ import (
 	"github.com/objectbox/objectbox-go/objectbox"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 func main() {
 	var options = cmd.GetOptions()
 
 	var executable = &ObjectBoxPerf{
-		path: options.Path,
+		path:           options.Path,
+		backendOptions: options.BackendOptions,
+		keepData:       options.KeepData,
+		existing:       options.Existing,
 	}
 
 	var executor = perf.CreateExecutor(executable)
@@ -42,38 +47,110 @@ func main() {
 
 // perf executable
 type ObjectBoxPerf struct {
-	path string
-	ob   *objectbox.ObjectBox
-	box  *obx.EntityBox
+	path           string
+	backendOptions map[string]string // builder options: max-db-size-kb, max-readers
+	keepData       bool              // whether Close() keeps the database directory
+	existing       bool              // whether Init() opens the existing database instead of removing it
+	ob             *objectbox.ObjectBox
+	box            *objectbox.Box // box of the current model, storing models.Entity objects for both models
+	indexed        bool           // whether box is the box of IndexedEntity instead of Entity
+
+	customerBox *obx.CustomerBox
+	orderBox    *obx.OrderBox
 }
 
 func (exec *ObjectBoxPerf) Init() error {
-	if err := os.RemoveAll(exec.path); err != nil {
-		return err
+	if !exec.existing {
+		if err := os.RemoveAll(exec.path); err != nil {
+			return err
+		}
 	}
 
+	return exec.Open()
+}
+
+// EffectiveDurability implements perf.DurabilityLimited: ObjectBox always syncs each commit to the disk, there's no
+// option to relax it, so the lower levels run the same as the full one.
+func (exec *ObjectBoxPerf) EffectiveDurability() string {
+	return perf.DurabilityFull
+}
+
+// Open opens the database in the directory, creating it if it doesn't exist
+func (exec *ObjectBoxPerf) Open() error {
 	var builder = objectbox.NewBuilder().
 		Directory(exec.path).
-		Model(obx.ObjectBoxModel())
+		Model(objectBoxModel())
+
+	for key, value := range exec.backendOptions {
+		var err error
+		switch key {
+		case "max-db-size-kb":
+			var size uint64
+			if size, err = strconv.ParseUint(value, 10, 64); err == nil {
+				builder.MaxSizeInKb(size)
+			}
+		case "max-readers":
+			var readers uint64
+			if readers, err = strconv.ParseUint(value, 10, 32); err == nil {
+				builder.MaxReaders(uint(readers))
+			}
+		default:
+			err = fmt.Errorf("unknown option, expected one of max-db-size-kb, max-readers")
+		}
+		if err != nil {
+			return fmt.Errorf("invalid backend option %s=%s: %s", key, value, err)
+		}
+	}
 
 	if ob, err := builder.Build(); err != nil {
 		return err
 	} else {
 		exec.ob = ob
-		exec.box = obx.BoxForEntity(ob)
+		exec.selectBox()
+		exec.customerBox = obx.BoxForCustomer(ob)
+		exec.orderBox = obx.BoxForOrder(ob)
 	}
 
 	return nil
 }
 
+func (exec *ObjectBoxPerf) Release() error {
+	exec.ob.Close()
+	return nil
+}
+
+func (exec *ObjectBoxPerf) SetModel(model string) error {
+	switch model {
+	case perf.ModelPlain:
+		exec.indexed = false
+	case perf.ModelIndexed:
+		exec.indexed = true
+	default:
+		return fmt.Errorf("unknown model %s", model)
+	}
+	exec.selectBox()
+	return nil
+}
+
+// selectBox sets the box of the current model after opening the database or changing the model
+func (exec *ObjectBoxPerf) selectBox() {
+	if exec.ob == nil {
+		return
+	} else if exec.indexed {
+		exec.box = exec.ob.InternalBox(obx.IndexedEntityBinding.Id)
+	} else {
+		exec.box = exec.ob.InternalBox(obx.EntityBinding.Id)
+	}
+}
+
 func (exec *ObjectBoxPerf) Close() error {
 	exec.ob.Close()
 
-	if err := os.RemoveAll(exec.path); err != nil {
-		return err
+	if exec.keepData {
+		return nil
 	}
 
-	return nil
+	return os.RemoveAll(exec.path)
 }
 
 func (exec *ObjectBoxPerf) Size() (uint64, error) {
@@ -89,7 +166,12 @@ func (exec *ObjectBoxPerf) RemoveAll() error {
 }
 
 func (exec *ObjectBoxPerf) RemoveBulk(items []*models.Entity) error {
-	if count, err := exec.box.RemoveMany(items...); err != nil {
+	var ids = make([]uint64, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+
+	if count, err := exec.box.RemoveIds(ids...); err != nil {
 		return err
 	} else if count != uint64(len(items)) {
 		return fmt.Errorf("removed only %d out of %d objects", count, len(items))
@@ -112,14 +194,302 @@ func (exec *ObjectBoxPerf) PutBulk(items []*models.Entity) error {
 	return err
 }
 
+// entities returns the objects read by the box of the current model, a []*models.Entity for both models
+func entities(objects interface{}, err error) ([]*models.Entity, error) {
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Entity), nil
+}
+
 func (exec *ObjectBoxPerf) ReadAll() ([]*models.Entity, error) {
-	return exec.box.GetAll()
+	return entities(exec.box.GetAll())
+}
+
+func (exec *ObjectBoxPerf) ReadChunk(afterId uint64, limit int) ([]*models.Entity, error) {
+	// query results are ordered by ID unless a different order is specified
+	return entities(exec.box.Query(exec.props().Id.GreaterThan(afterId)).Limit(uint64(limit)).Find())
+}
+
+// entityProperties has the same underlying type as obx.Entity_ and obx.IndexedEntity_
+type entityProperties struct {
+	Id      *objectbox.PropertyUint64
+	Int32   *objectbox.PropertyInt32
+	Int64   *objectbox.PropertyInt64
+	String  *objectbox.PropertyString
+	Float64 *objectbox.PropertyFloat64
+	Bytes   *objectbox.PropertyByteVector
+}
+
+// props returns the property helpers of the current model
+func (exec *ObjectBoxPerf) props() entityProperties {
+	if exec.indexed {
+		return entityProperties(obx.IndexedEntity_)
+	}
+	return entityProperties(obx.Entity_)
+}
+
+// find executes a query with the given conditions on the box of the current model
+func (exec *ObjectBoxPerf) find(conditions ...objectbox.Condition) ([]*models.Entity, error) {
+	return entities(exec.box.Query(conditions...).Find())
 }
 
 func (exec *ObjectBoxPerf) QueryIdBetween(min, max uint64) ([]*models.Entity, error) {
-	return exec.box.Query(obx.Entity_.Id.Between(min, max)).Find()
+	return exec.find(exec.props().Id.Between(min, max))
+}
+
+func (exec *ObjectBoxPerf) GetMany(ids []uint64) ([]*models.Entity, error) {
+	return entities(exec.box.GetManyExisting(ids...))
 }
 
 func (exec *ObjectBoxPerf) QueryStringPrefix(prefix string) ([]*models.Entity, error) {
-	return exec.box.Query(obx.Entity_.String.HasPrefix(prefix, true)).Find()
+	return exec.find(exec.props().String.HasPrefix(prefix, true))
+}
+
+func (exec *ObjectBoxPerf) QueryStringPrefixCaseInsensitive(prefix string) ([]*models.Entity, error) {
+	return exec.find(exec.props().String.HasPrefix(prefix, false))
+}
+
+func (exec *ObjectBoxPerf) QueryStringContains(text string) ([]*models.Entity, error) {
+	return exec.find(exec.props().String.Contains(text, true))
+}
+
+func (exec *ObjectBoxPerf) QueryInt32Equal(value int32) ([]*models.Entity, error) {
+	return exec.find(exec.props().Int32.Equals(value))
+}
+
+func (exec *ObjectBoxPerf) QueryInt64Greater(value int64) ([]*models.Entity, error) {
+	return exec.find(exec.props().Int64.GreaterThan(value))
+}
+
+func (exec *ObjectBoxPerf) QueryFloat64Between(min, max float64) ([]*models.Entity, error) {
+	return exec.find(exec.props().Float64.Between(min, max))
+}
+
+func (exec *ObjectBoxPerf) QueryInt32EqualAndInt64Greater(int32Value int32, int64Value int64) ([]*models.Entity,
+	error) {
+	var props = exec.props()
+	return exec.find(objectbox.All(props.Int32.Equals(int32Value), props.Int64.GreaterThan(int64Value)))
+}
+
+func (exec *ObjectBoxPerf) QueryInt32EqualOrFloat64Between(int32Value int32, min, max float64) ([]*models.Entity,
+	error) {
+	var props = exec.props()
+	return exec.find(objectbox.Any(props.Int32.Equals(int32Value), props.Float64.Between(min, max)))
+}
+
+func (exec *ObjectBoxPerf) QueryInt64Ordered(descending bool, offset, limit int) ([]*models.Entity, error) {
+	var props = exec.props()
+
+	// order by ID as well to make the order stable for objects with the same Int64 value
+	var order = []objectbox.Condition{props.Int64.OrderAsc(), props.Id.OrderAsc()}
+	if descending {
+		order = []objectbox.Condition{props.Int64.OrderDesc(), props.Id.OrderDesc()}
+	}
+
+	return entities(exec.box.Query(order...).Offset(uint64(offset)).Limit(uint64(limit)).Find())
+}
+
+func (exec *ObjectBoxPerf) Get(id uint64) (*models.Entity, error) {
+	if object, err := exec.box.Get(id); err != nil || object == nil {
+		return nil, err
+	} else {
+		return object.(*models.Entity), nil
+	}
+}
+
+func (exec *ObjectBoxPerf) Put(item *models.Entity) error {
+	_, err := exec.box.Put(item)
+	return err
+}
+
+func (exec *ObjectBoxPerf) Remove(item *models.Entity) error {
+	return exec.box.Remove(item)
+}
+
+// query creates a query with the given conditions on the box of the current model
+func (exec *ObjectBoxPerf) query(conditions ...objectbox.Condition) *objectbox.Query {
+	return exec.box.Query(conditions...)
+}
+
+func (exec *ObjectBoxPerf) Count() (uint64, error) {
+	return exec.box.Count()
+}
+
+func (exec *ObjectBoxPerf) CountInt64Greater(value int64) (uint64, error) {
+	return exec.query(exec.props().Int64.GreaterThan(value)).Count()
+}
+
+// aggregateInt64 executes all the aggregate functions on the Int64 property of objects matching the query
+func (exec *ObjectBoxPerf) aggregateInt64(query *objectbox.Query) (result perf.Int64Aggregates, err error) {
+	err = exec.ob.RunInReadTx(func() (err error) {
+		var pq = query.Property(exec.props().Int64)
+		if result.Count, err = pq.Count(); err != nil {
+			return err
+		} else if result.Sum, err = pq.Sum(); err != nil {
+			return err
+		} else if result.Min, err = pq.Min(); err != nil {
+			return err
+		} else if result.Max, err = pq.Max(); err != nil {
+			return err
+		}
+		result.Average, err = pq.Average()
+		return err
+	})
+	return result, err
+}
+
+// aggregateFloat64 executes all the aggregate functions on the Float64 property of objects matching the query
+func (exec *ObjectBoxPerf) aggregateFloat64(query *objectbox.Query) (result perf.Float64Aggregates, err error) {
+	err = exec.ob.RunInReadTx(func() (err error) {
+		var pq = query.Property(exec.props().Float64)
+		if result.Count, err = pq.Count(); err != nil {
+			return err
+		} else if result.Sum, err = pq.SumFloat64(); err != nil {
+			return err
+		} else if result.Min, err = pq.MinFloat64(); err != nil {
+			return err
+		} else if result.Max, err = pq.MaxFloat64(); err != nil {
+			return err
+		}
+		result.Average, err = pq.Average()
+		return err
+	})
+	return result, err
+}
+
+func (exec *ObjectBoxPerf) AggregateInt64() (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.query())
+}
+
+func (exec *ObjectBoxPerf) AggregateInt64Greater(value int64) (perf.Int64Aggregates, error) {
+	return exec.aggregateInt64(exec.query(exec.props().Int64.GreaterThan(value)))
+}
+
+func (exec *ObjectBoxPerf) AggregateFloat64() (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.query())
+}
+
+func (exec *ObjectBoxPerf) AggregateFloat64Between(min, max float64) (perf.Float64Aggregates, error) {
+	return exec.aggregateFloat64(exec.query(exec.props().Float64.Between(min, max)))
+}
+
+func (exec *ObjectBoxPerf) PutCustomers(customers []*models.Customer) error {
+	return exec.ob.RunInWriteTx(func() error {
+		// customers need to be put first so that their IDs can be set to the orders' to-one relation
+		if _, err := exec.customerBox.PutMany(customers); err != nil {
+			return err
+		}
+
+		var orders []*models.Order
+		for _, customer := range customers {
+			for _, order := range customer.Orders {
+				order.CustomerId = customer.Id
+			}
+			orders = append(orders, customer.Orders...)
+		}
+
+		_, err := exec.orderBox.PutMany(orders)
+		return err
+	})
+}
+
+func (exec *ObjectBoxPerf) ReadCustomers() (customers []*models.Customer, err error) {
+	err = exec.ob.RunInReadTx(func() error {
+		if customers, err = exec.customerBox.GetAll(); err != nil {
+			return err
+		}
+
+		var ids = make([]uint64, len(customers))
+		var byId = make(map[uint64]*models.Customer, len(customers))
+		for i, customer := range customers {
+			ids[i] = customer.Id
+			byId[customer.Id] = customer
+		}
+
+		// load the reverse side of the to-one relation, there are no back-links in ObjectBox Go
+		orders, err := exec.orderBox.Query(obx.Order_.CustomerId.In(ids...)).Find()
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			var customer = byId[order.CustomerId]
+			customer.Orders = append(customer.Orders, order)
+		}
+		return nil
+	})
+	return customers, err
+}
+
+func (exec *ObjectBoxPerf) QueryOrdersByCustomerName(name string) ([]*models.Order, error) {
+	return exec.orderBox.Query(obx.Order_.CustomerId.Link(obx.Customer_.Name.Equals(name, true))).Find()
+}
+
+func (exec *ObjectBoxPerf) RemoveAllCustomers() error {
+	return exec.ob.RunInWriteTx(func() error {
+		if err := exec.orderBox.RemoveAll(); err != nil {
+			return err
+		}
+		return exec.customerBox.RemoveAll()
+	})
+}
+
+// model
+
+// objectBoxModel is obx.ObjectBoxModel() with the IndexedEntity binding replaced by indexedEntityBinding
+func objectBoxModel() *objectbox.Model {
+	model := objectbox.NewModel()
+	model.GeneratorVersion(6)
+
+	model.RegisterBinding(obx.EntityBinding)
+	model.RegisterBinding(indexedEntityBinding{obx.IndexedEntityBinding})
+	model.RegisterBinding(obx.CustomerBinding)
+	model.RegisterBinding(obx.OrderBinding)
+	model.LastEntityId(4, 3686122037339039444)
+	model.LastIndexId(5, 4257795668451837780)
+
+	return model
+}
+
+// indexedEntityBinding stores models.Entity objects in the IndexedEntity box. Both types have the same fields, so
+// each object is converted by the generated binding, instead of converting the whole slice for each operation, which
+// would make the indexed model allocate more than the plain one regardless of the database.
+type indexedEntityBinding struct {
+	objectbox.ObjectBinding // the generated obx.IndexedEntityBinding
+}
+
+func (binding indexedEntityBinding) GetId(object interface{}) (uint64, error) {
+	return binding.ObjectBinding.GetId((*models.IndexedEntity)(object.(*models.Entity)))
+}
+
+func (binding indexedEntityBinding) SetId(object interface{}, id uint64) error {
+	return binding.ObjectBinding.SetId((*models.IndexedEntity)(object.(*models.Entity)), id)
+}
+
+func (binding indexedEntityBinding) PutRelated(ob *objectbox.ObjectBox, object interface{}, id uint64) error {
+	return binding.ObjectBinding.PutRelated(ob, (*models.IndexedEntity)(object.(*models.Entity)), id)
+}
+
+func (binding indexedEntityBinding) Flatten(object interface{}, fbb *flatbuffers.Builder, id uint64) error {
+	return binding.ObjectBinding.Flatten((*models.IndexedEntity)(object.(*models.Entity)), fbb, id)
+}
+
+func (binding indexedEntityBinding) Load(ob *objectbox.ObjectBox, bytes []byte) (interface{}, error) {
+	if object, err := binding.ObjectBinding.Load(ob, bytes); err != nil {
+		return nil, err
+	} else {
+		return (*models.Entity)(object.(*models.IndexedEntity)), nil
+	}
+}
+
+func (binding indexedEntityBinding) MakeSlice(capacity int) interface{} {
+	return make([]*models.Entity, 0, capacity)
+}
+
+func (binding indexedEntityBinding) AppendToSlice(slice interface{}, object interface{}) interface{} {
+	if object == nil {
+		return append(slice.([]*models.Entity), nil)
+	}
+	return append(slice.([]*models.Entity), object.(*models.Entity))
 }
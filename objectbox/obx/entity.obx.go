@@ -30,6 +30,7 @@ var Entity_ = struct {
 	Int64   *objectbox.PropertyInt64
 	String  *objectbox.PropertyString
 	Float64 *objectbox.PropertyFloat64
+	Bytes   *objectbox.PropertyByteVector
 }{
 	Id: &objectbox.PropertyUint64{
 		BaseProperty: &objectbox.BaseProperty{
@@ -61,6 +62,12 @@ var Entity_ = struct {
 			Entity: &EntityBinding.Entity,
 		},
 	},
+	Bytes: &objectbox.PropertyByteVector{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     6,
+			Entity: &EntityBinding.Entity,
+		},
+	},
 }
 
 // GeneratorVersion is called by ObjectBox to verify the compatibility of the generator used to generate this code
@@ -77,7 +84,8 @@ func (entity_EntityInfo) AddToModel(model *objectbox.Model) {
 	model.Property("Int64", 6, 3, 610993008881667918)
 	model.Property("String", 9, 4, 3065940618737056934)
 	model.Property("Float64", 8, 5, 1697483777723125251)
-	model.EntityLastPropertyId(5, 1697483777723125251)
+	model.Property("Bytes", 23, 6, 314235715511097397)
+	model.EntityLastPropertyId(6, 314235715511097397)
 }
 
 // GetId is called by ObjectBox during Put operations to check for existing ID on an object
@@ -100,14 +108,16 @@ func (entity_EntityInfo) PutRelated(ob *objectbox.ObjectBox, object interface{},
 func (entity_EntityInfo) Flatten(object interface{}, fbb *flatbuffers.Builder, id uint64) error {
 	obj := object.(*models.Entity)
 	var offsetString = fbutils.CreateStringOffset(fbb, obj.String)
+	var offsetBytes = fbutils.CreateByteVectorOffset(fbb, obj.Bytes)
 
 	// build the FlatBuffers object
-	fbb.StartObject(5)
+	fbb.StartObject(6)
 	fbutils.SetUint64Slot(fbb, 0, id)
 	fbutils.SetInt32Slot(fbb, 1, obj.Int32)
 	fbutils.SetInt64Slot(fbb, 2, obj.Int64)
 	fbutils.SetUOffsetTSlot(fbb, 3, offsetString)
 	fbutils.SetFloat64Slot(fbb, 4, obj.Float64)
+	fbutils.SetUOffsetTSlot(fbb, 5, offsetBytes)
 	return nil
 }
 
@@ -130,6 +140,7 @@ func (entity_EntityInfo) Load(ob *objectbox.ObjectBox, bytes []byte) (interface{
 		Int64:   fbutils.GetInt64Slot(table, 8),
 		String:  fbutils.GetStringSlot(table, 10),
 		Float64: fbutils.GetFloat64Slot(table, 12),
+		Bytes:   fbutils.GetByteVectorSlot(table, 14),
 	}, nil
 }
 
@@ -342,7 +353,8 @@ func (asyncBox *EntityAsyncBox) Remove(object *models.Entity) error {
 // Query provides a way to search stored objects
 //
 // For example, you can find all Entity which Id is either 42 or 47:
-// 		box.Query(Entity_.Id.In(42, 47)).Find()
+//
+// box.Query(Entity_.Id.In(42, 47)).Find()
 type EntityQuery struct {
 	*objectbox.Query
 }
@@ -367,3 +379,378 @@ func (query *EntityQuery) Limit(limit uint64) *EntityQuery {
 	query.Query.Limit(limit)
 	return query
 }
+
+type indexedEntity_EntityInfo struct {
+	objectbox.Entity
+	Uid uint64
+}
+
+var IndexedEntityBinding = indexedEntity_EntityInfo{
+	Entity: objectbox.Entity{
+		Id: 2,
+	},
+	Uid: 4157340184108130666,
+}
+
+// IndexedEntity_ contains type-based Property helpers to facilitate some common operations such as Queries.
+var IndexedEntity_ = struct {
+	Id      *objectbox.PropertyUint64
+	Int32   *objectbox.PropertyInt32
+	Int64   *objectbox.PropertyInt64
+	String  *objectbox.PropertyString
+	Float64 *objectbox.PropertyFloat64
+	Bytes   *objectbox.PropertyByteVector
+}{
+	Id: &objectbox.PropertyUint64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     1,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+	Int32: &objectbox.PropertyInt32{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     2,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+	Int64: &objectbox.PropertyInt64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     3,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+	String: &objectbox.PropertyString{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     4,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+	Float64: &objectbox.PropertyFloat64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     5,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+	Bytes: &objectbox.PropertyByteVector{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     6,
+			Entity: &IndexedEntityBinding.Entity,
+		},
+	},
+}
+
+// GeneratorVersion is called by ObjectBox to verify the compatibility of the generator used to generate this code
+func (indexedEntity_EntityInfo) GeneratorVersion() int {
+	return 6
+}
+
+// AddToModel is called by ObjectBox during model build
+func (indexedEntity_EntityInfo) AddToModel(model *objectbox.Model) {
+	model.Entity("IndexedEntity", 2, 4157340184108130666)
+	model.Property("Id", 6, 1, 4671647492872123098)
+	model.PropertyFlags(1)
+	model.Property("Int32", 5, 2, 7520095919113295192)
+	model.PropertyFlags(8)
+	model.PropertyIndex(1, 2069373026768587124)
+	model.Property("Int64", 6, 3, 6953057942235925472)
+	model.PropertyFlags(8)
+	model.PropertyIndex(2, 492573264700232168)
+	model.Property("String", 9, 4, 8144302661881779082)
+	model.PropertyFlags(8)
+	model.PropertyIndex(3, 3451748391222279946)
+	model.Property("Float64", 8, 5, 1746972673772509821)
+	model.Property("Bytes", 23, 6, 1675023040097963843)
+	model.EntityLastPropertyId(6, 1675023040097963843)
+}
+
+// GetId is called by ObjectBox during Put operations to check for existing ID on an object
+func (indexedEntity_EntityInfo) GetId(object interface{}) (uint64, error) {
+	return object.(*models.IndexedEntity).Id, nil
+}
+
+// SetId is called by ObjectBox during Put to update an ID on an object that has just been inserted
+func (indexedEntity_EntityInfo) SetId(object interface{}, id uint64) error {
+	object.(*models.IndexedEntity).Id = id
+	return nil
+}
+
+// PutRelated is called by ObjectBox to put related entities before the object itself is flattened and put
+func (indexedEntity_EntityInfo) PutRelated(ob *objectbox.ObjectBox, object interface{}, id uint64) error {
+	return nil
+}
+
+// Flatten is called by ObjectBox to transform an object to a FlatBuffer
+func (indexedEntity_EntityInfo) Flatten(object interface{}, fbb *flatbuffers.Builder, id uint64) error {
+	obj := object.(*models.IndexedEntity)
+	var offsetString = fbutils.CreateStringOffset(fbb, obj.String)
+	var offsetBytes = fbutils.CreateByteVectorOffset(fbb, obj.Bytes)
+
+	// build the FlatBuffers object
+	fbb.StartObject(6)
+	fbutils.SetUint64Slot(fbb, 0, id)
+	fbutils.SetInt32Slot(fbb, 1, obj.Int32)
+	fbutils.SetInt64Slot(fbb, 2, obj.Int64)
+	fbutils.SetUOffsetTSlot(fbb, 3, offsetString)
+	fbutils.SetFloat64Slot(fbb, 4, obj.Float64)
+	fbutils.SetUOffsetTSlot(fbb, 5, offsetBytes)
+	return nil
+}
+
+// Load is called by ObjectBox to load an object from a FlatBuffer
+func (indexedEntity_EntityInfo) Load(ob *objectbox.ObjectBox, bytes []byte) (interface{}, error) {
+	if len(bytes) == 0 { // sanity check, should "never" happen
+		return nil, errors.New("can't deserialize an object of type 'IndexedEntity' - no data received")
+	}
+
+	var table = &flatbuffers.Table{
+		Bytes: bytes,
+		Pos:   flatbuffers.GetUOffsetT(bytes),
+	}
+
+	var propId = table.GetUint64Slot(4, 0)
+
+	return &models.IndexedEntity{
+		Id:      propId,
+		Int32:   fbutils.GetInt32Slot(table, 6),
+		Int64:   fbutils.GetInt64Slot(table, 8),
+		String:  fbutils.GetStringSlot(table, 10),
+		Float64: fbutils.GetFloat64Slot(table, 12),
+		Bytes:   fbutils.GetByteVectorSlot(table, 14),
+	}, nil
+}
+
+// MakeSlice is called by ObjectBox to construct a new slice to hold the read objects
+func (indexedEntity_EntityInfo) MakeSlice(capacity int) interface{} {
+	return make([]*models.IndexedEntity, 0, capacity)
+}
+
+// AppendToSlice is called by ObjectBox to fill the slice of the read objects
+func (indexedEntity_EntityInfo) AppendToSlice(slice interface{}, object interface{}) interface{} {
+	if object == nil {
+		return append(slice.([]*models.IndexedEntity), nil)
+	}
+	return append(slice.([]*models.IndexedEntity), object.(*models.IndexedEntity))
+}
+
+// Box provides CRUD access to IndexedEntity objects
+type IndexedEntityBox struct {
+	*objectbox.Box
+}
+
+// BoxForIndexedEntity opens a box of IndexedEntity objects
+func BoxForIndexedEntity(ob *objectbox.ObjectBox) *IndexedEntityBox {
+	return &IndexedEntityBox{
+		Box: ob.InternalBox(2),
+	}
+}
+
+// Put synchronously inserts/updates a single object.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the IndexedEntity.Id property on the passed object will be assigned the new ID as well.
+func (box *IndexedEntityBox) Put(object *models.IndexedEntity) (uint64, error) {
+	return box.Box.Put(object)
+}
+
+// Insert synchronously inserts a single object. As opposed to Put, Insert will fail if given an ID that already exists.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the IndexedEntity.Id property on the passed object will be assigned the new ID as well.
+func (box *IndexedEntityBox) Insert(object *models.IndexedEntity) (uint64, error) {
+	return box.Box.Insert(object)
+}
+
+// Update synchronously updates a single object.
+// As opposed to Put, Update will fail if an object with the same ID is not found in the database.
+func (box *IndexedEntityBox) Update(object *models.IndexedEntity) error {
+	return box.Box.Update(object)
+}
+
+// PutAsync asynchronously inserts/updates a single object.
+// Deprecated: use box.Async().Put() instead
+func (box *IndexedEntityBox) PutAsync(object *models.IndexedEntity) (uint64, error) {
+	return box.Box.PutAsync(object)
+}
+
+// PutMany inserts multiple objects in single transaction.
+// In case Ids are not set on the objects, they would be assigned automatically (auto-increment).
+//
+// Returns: IDs of the put objects (in the same order).
+// When inserting, the IndexedEntity.Id property on the objects in the slice will be assigned the new IDs as well.
+//
+// Note: In case an error occurs during the transaction, some of the objects may already have the IndexedEntity.Id assigned
+// even though the transaction has been rolled back and the objects are not stored under those IDs.
+//
+// Note: The slice may be empty or even nil; in both cases, an empty IDs slice and no error is returned.
+func (box *IndexedEntityBox) PutMany(objects []*models.IndexedEntity) ([]uint64, error) {
+	return box.Box.PutMany(objects)
+}
+
+// Get reads a single object.
+//
+// Returns nil (and no error) in case the object with the given ID doesn't exist.
+func (box *IndexedEntityBox) Get(id uint64) (*models.IndexedEntity, error) {
+	object, err := box.Box.Get(id)
+	if err != nil {
+		return nil, err
+	} else if object == nil {
+		return nil, nil
+	}
+	return object.(*models.IndexedEntity), nil
+}
+
+// GetMany reads multiple objects at once.
+// If any of the objects doesn't exist, its position in the return slice is nil
+func (box *IndexedEntityBox) GetMany(ids ...uint64) ([]*models.IndexedEntity, error) {
+	objects, err := box.Box.GetMany(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.IndexedEntity), nil
+}
+
+// GetManyExisting reads multiple objects at once, skipping those that do not exist.
+func (box *IndexedEntityBox) GetManyExisting(ids ...uint64) ([]*models.IndexedEntity, error) {
+	objects, err := box.Box.GetManyExisting(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.IndexedEntity), nil
+}
+
+// GetAll reads all stored objects
+func (box *IndexedEntityBox) GetAll() ([]*models.IndexedEntity, error) {
+	objects, err := box.Box.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.IndexedEntity), nil
+}
+
+// Remove deletes a single object
+func (box *IndexedEntityBox) Remove(object *models.IndexedEntity) error {
+	return box.Box.Remove(object)
+}
+
+// RemoveMany deletes multiple objects at once.
+// Returns the number of deleted object or error on failure.
+// Note that this method will not fail if an object is not found (e.g. already removed).
+// In case you need to strictly check whether all of the objects exist before removing them,
+// you can execute multiple box.Contains() and box.Remove() inside a single write transaction.
+func (box *IndexedEntityBox) RemoveMany(objects ...*models.IndexedEntity) (uint64, error) {
+	var ids = make([]uint64, len(objects))
+	for k, object := range objects {
+		ids[k] = object.Id
+	}
+	return box.Box.RemoveIds(ids...)
+}
+
+// Creates a query with the given conditions. Use the fields of the IndexedEntity_ struct to create conditions.
+// Keep the *IndexedEntityQuery if you intend to execute the query multiple times.
+// Note: this function panics if you try to create illegal queries; e.g. use properties of an alien type.
+// This is typically a programming error. Use QueryOrError instead if you want the explicit error check.
+func (box *IndexedEntityBox) Query(conditions ...objectbox.Condition) *IndexedEntityQuery {
+	return &IndexedEntityQuery{
+		box.Box.Query(conditions...),
+	}
+}
+
+// Creates a query with the given conditions. Use the fields of the IndexedEntity_ struct to create conditions.
+// Keep the *IndexedEntityQuery if you intend to execute the query multiple times.
+func (box *IndexedEntityBox) QueryOrError(conditions ...objectbox.Condition) (*IndexedEntityQuery, error) {
+	if query, err := box.Box.QueryOrError(conditions...); err != nil {
+		return nil, err
+	} else {
+		return &IndexedEntityQuery{query}, nil
+	}
+}
+
+// Async provides access to the default Async Box for asynchronous operations. See IndexedEntityAsyncBox for more information.
+func (box *IndexedEntityBox) Async() *IndexedEntityAsyncBox {
+	return &IndexedEntityAsyncBox{AsyncBox: box.Box.Async()}
+}
+
+// IndexedEntityAsyncBox provides asynchronous operations on IndexedEntity objects.
+//
+// Asynchronous operations are executed on a separate internal thread for better performance.
+//
+// There are two main use cases:
+//
+// 1) "execute & forget:" you gain faster put/remove operations as you don't have to wait for the transaction to finish.
+//
+// 2) Many small transactions: if your write load is typically a lot of individual puts that happen in parallel,
+// this will merge small transactions into bigger ones. This results in a significant gain in overall throughput.
+//
+// In situations with (extremely) high async load, an async method may be throttled (~1ms) or delayed up to 1 second.
+// In the unlikely event that the object could still not be enqueued (full queue), an error will be returned.
+//
+// Note that async methods do not give you hard durability guarantees like the synchronous Box provides.
+// There is a small time window in which the data may not have been committed durably yet.
+type IndexedEntityAsyncBox struct {
+	*objectbox.AsyncBox
+}
+
+// AsyncBoxForIndexedEntity creates a new async box with the given operation timeout in case an async queue is full.
+// The returned struct must be freed explicitly using the Close() method.
+// It's usually preferable to use IndexedEntityBox::Async() which takes care of resource management and doesn't require closing.
+func AsyncBoxForIndexedEntity(ob *objectbox.ObjectBox, timeoutMs uint64) *IndexedEntityAsyncBox {
+	var async, err = objectbox.NewAsyncBox(ob, 2, timeoutMs)
+	if err != nil {
+		panic("Could not create async box for entity ID 2: %s" + err.Error())
+	}
+	return &IndexedEntityAsyncBox{AsyncBox: async}
+}
+
+// Put inserts/updates a single object asynchronously.
+// When inserting a new object, the Id property on the passed object will be assigned the new ID the entity would hold
+// if the insert is ultimately successful. The newly assigned ID may not become valid if the insert fails.
+func (asyncBox *IndexedEntityAsyncBox) Put(object *models.IndexedEntity) (uint64, error) {
+	return asyncBox.AsyncBox.Put(object)
+}
+
+// Insert a single object asynchronously.
+// The Id property on the passed object will be assigned the new ID the entity would hold if the insert is ultimately
+// successful. The newly assigned ID may not become valid if the insert fails.
+// Fails silently if an object with the same ID already exists (this error is not returned).
+func (asyncBox *IndexedEntityAsyncBox) Insert(object *models.IndexedEntity) (id uint64, err error) {
+	return asyncBox.AsyncBox.Insert(object)
+}
+
+// Update a single object asynchronously.
+// The object must already exists or the update fails silently (without an error returned).
+func (asyncBox *IndexedEntityAsyncBox) Update(object *models.IndexedEntity) error {
+	return asyncBox.AsyncBox.Update(object)
+}
+
+// Remove deletes a single object asynchronously.
+func (asyncBox *IndexedEntityAsyncBox) Remove(object *models.IndexedEntity) error {
+	return asyncBox.AsyncBox.Remove(object)
+}
+
+// Query provides a way to search stored objects
+//
+// For example, you can find all IndexedEntity which Id is either 42 or 47:
+//
+// box.Query(IndexedEntity_.Id.In(42, 47)).Find()
+type IndexedEntityQuery struct {
+	*objectbox.Query
+}
+
+// Find returns all objects matching the query
+func (query *IndexedEntityQuery) Find() ([]*models.IndexedEntity, error) {
+	objects, err := query.Query.Find()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.IndexedEntity), nil
+}
+
+// Offset defines the index of the first object to process (how many objects to skip)
+func (query *IndexedEntityQuery) Offset(offset uint64) *IndexedEntityQuery {
+	query.Query.Offset(offset)
+	return query
+}
+
+// Limit sets the number of elements to process by the query
+func (query *IndexedEntityQuery) Limit(limit uint64) *IndexedEntityQuery {
+	query.Query.Limit(limit)
+	return query
+}
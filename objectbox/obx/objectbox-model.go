@@ -13,7 +13,11 @@ func ObjectBoxModel() *objectbox.Model {
 	model.GeneratorVersion(6)
 
 	model.RegisterBinding(EntityBinding)
-	model.LastEntityId(1, 5847816654868029727)
+	model.RegisterBinding(IndexedEntityBinding)
+	model.RegisterBinding(CustomerBinding)
+	model.RegisterBinding(OrderBinding)
+	model.LastEntityId(4, 3686122037339039444)
+	model.LastIndexId(5, 4257795668451837780)
 
 	return model
 }
@@ -0,0 +1,694 @@
+// Code generated by ObjectBox; DO NOT EDIT.
+// Learn more about defining entities and generating this file - visit https://golang.objectbox.io/entity-annotations
+
+package obx
+
+import (
+	"errors"
+	"github.com/google/flatbuffers/go"
+	"github.com/objectbox/objectbox-go-performance/internal/models"
+	"github.com/objectbox/objectbox-go/objectbox"
+	"github.com/objectbox/objectbox-go/objectbox/fbutils"
+)
+
+type customer_EntityInfo struct {
+	objectbox.Entity
+	Uid uint64
+}
+
+var CustomerBinding = customer_EntityInfo{
+	Entity: objectbox.Entity{
+		Id: 3,
+	},
+	Uid: 2091745715736969027,
+}
+
+// Customer_ contains type-based Property helpers to facilitate some common operations such as Queries.
+var Customer_ = struct {
+	Id   *objectbox.PropertyUint64
+	Name *objectbox.PropertyString
+}{
+	Id: &objectbox.PropertyUint64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     1,
+			Entity: &CustomerBinding.Entity,
+		},
+	},
+	Name: &objectbox.PropertyString{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     2,
+			Entity: &CustomerBinding.Entity,
+		},
+	},
+}
+
+// GeneratorVersion is called by ObjectBox to verify the compatibility of the generator used to generate this code
+func (customer_EntityInfo) GeneratorVersion() int {
+	return 6
+}
+
+// AddToModel is called by ObjectBox during model build
+func (customer_EntityInfo) AddToModel(model *objectbox.Model) {
+	model.Entity("Customer", 3, 2091745715736969027)
+	model.Property("Id", 6, 1, 289994167564105099)
+	model.PropertyFlags(1)
+	model.Property("Name", 9, 2, 1095711622332879203)
+	model.PropertyFlags(2048)
+	model.PropertyIndex(4, 1838832716326810392)
+	model.EntityLastPropertyId(2, 1095711622332879203)
+}
+
+// GetId is called by ObjectBox during Put operations to check for existing ID on an object
+func (customer_EntityInfo) GetId(object interface{}) (uint64, error) {
+	return object.(*models.Customer).Id, nil
+}
+
+// SetId is called by ObjectBox during Put to update an ID on an object that has just been inserted
+func (customer_EntityInfo) SetId(object interface{}, id uint64) error {
+	object.(*models.Customer).Id = id
+	return nil
+}
+
+// PutRelated is called by ObjectBox to put related entities before the object itself is flattened and put
+func (customer_EntityInfo) PutRelated(ob *objectbox.ObjectBox, object interface{}, id uint64) error {
+	return nil
+}
+
+// Flatten is called by ObjectBox to transform an object to a FlatBuffer
+func (customer_EntityInfo) Flatten(object interface{}, fbb *flatbuffers.Builder, id uint64) error {
+	obj := object.(*models.Customer)
+	var offsetName = fbutils.CreateStringOffset(fbb, obj.Name)
+
+	// build the FlatBuffers object
+	fbb.StartObject(2)
+	fbutils.SetUint64Slot(fbb, 0, id)
+	fbutils.SetUOffsetTSlot(fbb, 1, offsetName)
+	return nil
+}
+
+// Load is called by ObjectBox to load an object from a FlatBuffer
+func (customer_EntityInfo) Load(ob *objectbox.ObjectBox, bytes []byte) (interface{}, error) {
+	if len(bytes) == 0 { // sanity check, should "never" happen
+		return nil, errors.New("can't deserialize an object of type 'Customer' - no data received")
+	}
+
+	var table = &flatbuffers.Table{
+		Bytes: bytes,
+		Pos:   flatbuffers.GetUOffsetT(bytes),
+	}
+
+	var propId = table.GetUint64Slot(4, 0)
+
+	return &models.Customer{
+		Id:   propId,
+		Name: fbutils.GetStringSlot(table, 6),
+	}, nil
+}
+
+// MakeSlice is called by ObjectBox to construct a new slice to hold the read objects
+func (customer_EntityInfo) MakeSlice(capacity int) interface{} {
+	return make([]*models.Customer, 0, capacity)
+}
+
+// AppendToSlice is called by ObjectBox to fill the slice of the read objects
+func (customer_EntityInfo) AppendToSlice(slice interface{}, object interface{}) interface{} {
+	if object == nil {
+		return append(slice.([]*models.Customer), nil)
+	}
+	return append(slice.([]*models.Customer), object.(*models.Customer))
+}
+
+// Box provides CRUD access to Customer objects
+type CustomerBox struct {
+	*objectbox.Box
+}
+
+// BoxForCustomer opens a box of Customer objects
+func BoxForCustomer(ob *objectbox.ObjectBox) *CustomerBox {
+	return &CustomerBox{
+		Box: ob.InternalBox(3),
+	}
+}
+
+// Put synchronously inserts/updates a single object.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the Customer.Id property on the passed object will be assigned the new ID as well.
+func (box *CustomerBox) Put(object *models.Customer) (uint64, error) {
+	return box.Box.Put(object)
+}
+
+// Insert synchronously inserts a single object. As opposed to Put, Insert will fail if given an ID that already exists.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the Customer.Id property on the passed object will be assigned the new ID as well.
+func (box *CustomerBox) Insert(object *models.Customer) (uint64, error) {
+	return box.Box.Insert(object)
+}
+
+// Update synchronously updates a single object.
+// As opposed to Put, Update will fail if an object with the same ID is not found in the database.
+func (box *CustomerBox) Update(object *models.Customer) error {
+	return box.Box.Update(object)
+}
+
+// PutAsync asynchronously inserts/updates a single object.
+// Deprecated: use box.Async().Put() instead
+func (box *CustomerBox) PutAsync(object *models.Customer) (uint64, error) {
+	return box.Box.PutAsync(object)
+}
+
+// PutMany inserts multiple objects in single transaction.
+// In case Ids are not set on the objects, they would be assigned automatically (auto-increment).
+//
+// Returns: IDs of the put objects (in the same order).
+// When inserting, the Customer.Id property on the objects in the slice will be assigned the new IDs as well.
+//
+// Note: In case an error occurs during the transaction, some of the objects may already have the Customer.Id assigned
+// even though the transaction has been rolled back and the objects are not stored under those IDs.
+//
+// Note: The slice may be empty or even nil; in both cases, an empty IDs slice and no error is returned.
+func (box *CustomerBox) PutMany(objects []*models.Customer) ([]uint64, error) {
+	return box.Box.PutMany(objects)
+}
+
+// Get reads a single object.
+//
+// Returns nil (and no error) in case the object with the given ID doesn't exist.
+func (box *CustomerBox) Get(id uint64) (*models.Customer, error) {
+	object, err := box.Box.Get(id)
+	if err != nil {
+		return nil, err
+	} else if object == nil {
+		return nil, nil
+	}
+	return object.(*models.Customer), nil
+}
+
+// GetMany reads multiple objects at once.
+// If any of the objects doesn't exist, its position in the return slice is nil
+func (box *CustomerBox) GetMany(ids ...uint64) ([]*models.Customer, error) {
+	objects, err := box.Box.GetMany(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Customer), nil
+}
+
+// GetManyExisting reads multiple objects at once, skipping those that do not exist.
+func (box *CustomerBox) GetManyExisting(ids ...uint64) ([]*models.Customer, error) {
+	objects, err := box.Box.GetManyExisting(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Customer), nil
+}
+
+// GetAll reads all stored objects
+func (box *CustomerBox) GetAll() ([]*models.Customer, error) {
+	objects, err := box.Box.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Customer), nil
+}
+
+// Remove deletes a single object
+func (box *CustomerBox) Remove(object *models.Customer) error {
+	return box.Box.Remove(object)
+}
+
+// RemoveMany deletes multiple objects at once.
+// Returns the number of deleted object or error on failure.
+// Note that this method will not fail if an object is not found (e.g. already removed).
+// In case you need to strictly check whether all of the objects exist before removing them,
+// you can execute multiple box.Contains() and box.Remove() inside a single write transaction.
+func (box *CustomerBox) RemoveMany(objects ...*models.Customer) (uint64, error) {
+	var ids = make([]uint64, len(objects))
+	for k, object := range objects {
+		ids[k] = object.Id
+	}
+	return box.Box.RemoveIds(ids...)
+}
+
+// Creates a query with the given conditions. Use the fields of the Customer_ struct to create conditions.
+// Keep the *CustomerQuery if you intend to execute the query multiple times.
+// Note: this function panics if you try to create illegal queries; e.g. use properties of an alien type.
+// This is typically a programming error. Use QueryOrError instead if you want the explicit error check.
+func (box *CustomerBox) Query(conditions ...objectbox.Condition) *CustomerQuery {
+	return &CustomerQuery{
+		box.Box.Query(conditions...),
+	}
+}
+
+// Creates a query with the given conditions. Use the fields of the Customer_ struct to create conditions.
+// Keep the *CustomerQuery if you intend to execute the query multiple times.
+func (box *CustomerBox) QueryOrError(conditions ...objectbox.Condition) (*CustomerQuery, error) {
+	if query, err := box.Box.QueryOrError(conditions...); err != nil {
+		return nil, err
+	} else {
+		return &CustomerQuery{query}, nil
+	}
+}
+
+// Async provides access to the default Async Box for asynchronous operations. See CustomerAsyncBox for more information.
+func (box *CustomerBox) Async() *CustomerAsyncBox {
+	return &CustomerAsyncBox{AsyncBox: box.Box.Async()}
+}
+
+// CustomerAsyncBox provides asynchronous operations on Customer objects.
+//
+// Asynchronous operations are executed on a separate internal thread for better performance.
+//
+// There are two main use cases:
+//
+// 1) "execute & forget:" you gain faster put/remove operations as you don't have to wait for the transaction to finish.
+//
+// 2) Many small transactions: if your write load is typically a lot of individual puts that happen in parallel,
+// this will merge small transactions into bigger ones. This results in a significant gain in overall throughput.
+//
+// In situations with (extremely) high async load, an async method may be throttled (~1ms) or delayed up to 1 second.
+// In the unlikely event that the object could still not be enqueued (full queue), an error will be returned.
+//
+// Note that async methods do not give you hard durability guarantees like the synchronous Box provides.
+// There is a small time window in which the data may not have been committed durably yet.
+type CustomerAsyncBox struct {
+	*objectbox.AsyncBox
+}
+
+// AsyncBoxForCustomer creates a new async box with the given operation timeout in case an async queue is full.
+// The returned struct must be freed explicitly using the Close() method.
+// It's usually preferable to use CustomerBox::Async() which takes care of resource management and doesn't require closing.
+func AsyncBoxForCustomer(ob *objectbox.ObjectBox, timeoutMs uint64) *CustomerAsyncBox {
+	var async, err = objectbox.NewAsyncBox(ob, 3, timeoutMs)
+	if err != nil {
+		panic("Could not create async box for entity ID 3: %s" + err.Error())
+	}
+	return &CustomerAsyncBox{AsyncBox: async}
+}
+
+// Put inserts/updates a single object asynchronously.
+// When inserting a new object, the Id property on the passed object will be assigned the new ID the entity would hold
+// if the insert is ultimately successful. The newly assigned ID may not become valid if the insert fails.
+func (asyncBox *CustomerAsyncBox) Put(object *models.Customer) (uint64, error) {
+	return asyncBox.AsyncBox.Put(object)
+}
+
+// Insert a single object asynchronously.
+// The Id property on the passed object will be assigned the new ID the entity would hold if the insert is ultimately
+// successful. The newly assigned ID may not become valid if the insert fails.
+// Fails silently if an object with the same ID already exists (this error is not returned).
+func (asyncBox *CustomerAsyncBox) Insert(object *models.Customer) (id uint64, err error) {
+	return asyncBox.AsyncBox.Insert(object)
+}
+
+// Update a single object asynchronously.
+// The object must already exists or the update fails silently (without an error returned).
+func (asyncBox *CustomerAsyncBox) Update(object *models.Customer) error {
+	return asyncBox.AsyncBox.Update(object)
+}
+
+// Remove deletes a single object asynchronously.
+func (asyncBox *CustomerAsyncBox) Remove(object *models.Customer) error {
+	return asyncBox.AsyncBox.Remove(object)
+}
+
+// Query provides a way to search stored objects
+//
+// For example, you can find all Customer which Id is either 42 or 47:
+//
+// box.Query(Customer_.Id.In(42, 47)).Find()
+type CustomerQuery struct {
+	*objectbox.Query
+}
+
+// Find returns all objects matching the query
+func (query *CustomerQuery) Find() ([]*models.Customer, error) {
+	objects, err := query.Query.Find()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Customer), nil
+}
+
+// Offset defines the index of the first object to process (how many objects to skip)
+func (query *CustomerQuery) Offset(offset uint64) *CustomerQuery {
+	query.Query.Offset(offset)
+	return query
+}
+
+// Limit sets the number of elements to process by the query
+func (query *CustomerQuery) Limit(limit uint64) *CustomerQuery {
+	query.Query.Limit(limit)
+	return query
+}
+
+type order_EntityInfo struct {
+	objectbox.Entity
+	Uid uint64
+}
+
+var OrderBinding = order_EntityInfo{
+	Entity: objectbox.Entity{
+		Id: 4,
+	},
+	Uid: 3686122037339039444,
+}
+
+// Order_ contains type-based Property helpers to facilitate some common operations such as Queries.
+var Order_ = struct {
+	Id         *objectbox.PropertyUint64
+	CustomerId *objectbox.RelationToOne
+	Date       *objectbox.PropertyInt64
+	Amount     *objectbox.PropertyFloat64
+}{
+	Id: &objectbox.PropertyUint64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     1,
+			Entity: &OrderBinding.Entity,
+		},
+	},
+	CustomerId: &objectbox.RelationToOne{
+		Property: &objectbox.BaseProperty{
+			Id:     2,
+			Entity: &OrderBinding.Entity,
+		},
+		Target: &CustomerBinding.Entity,
+	},
+	Date: &objectbox.PropertyInt64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     3,
+			Entity: &OrderBinding.Entity,
+		},
+	},
+	Amount: &objectbox.PropertyFloat64{
+		BaseProperty: &objectbox.BaseProperty{
+			Id:     4,
+			Entity: &OrderBinding.Entity,
+		},
+	},
+}
+
+// GeneratorVersion is called by ObjectBox to verify the compatibility of the generator used to generate this code
+func (order_EntityInfo) GeneratorVersion() int {
+	return 6
+}
+
+// AddToModel is called by ObjectBox during model build
+func (order_EntityInfo) AddToModel(model *objectbox.Model) {
+	model.Entity("Order", 4, 3686122037339039444)
+	model.Property("Id", 6, 1, 6979155642683793641)
+	model.PropertyFlags(1)
+	model.Property("CustomerId", 11, 2, 7222185244100452417)
+	model.PropertyFlags(520)
+	model.PropertyRelation("Customer", 5, 4257795668451837780)
+	model.Property("Date", 6, 3, 2199726120914250117)
+	model.Property("Amount", 8, 4, 8607774028520756486)
+	model.EntityLastPropertyId(4, 8607774028520756486)
+}
+
+// GetId is called by ObjectBox during Put operations to check for existing ID on an object
+func (order_EntityInfo) GetId(object interface{}) (uint64, error) {
+	return object.(*models.Order).Id, nil
+}
+
+// SetId is called by ObjectBox during Put to update an ID on an object that has just been inserted
+func (order_EntityInfo) SetId(object interface{}, id uint64) error {
+	object.(*models.Order).Id = id
+	return nil
+}
+
+// PutRelated is called by ObjectBox to put related entities before the object itself is flattened and put
+func (order_EntityInfo) PutRelated(ob *objectbox.ObjectBox, object interface{}, id uint64) error {
+	return nil
+}
+
+// Flatten is called by ObjectBox to transform an object to a FlatBuffer
+func (order_EntityInfo) Flatten(object interface{}, fbb *flatbuffers.Builder, id uint64) error {
+	obj := object.(*models.Order)
+
+	var rIdCustomerId = obj.CustomerId
+
+	// build the FlatBuffers object
+	fbb.StartObject(4)
+	fbutils.SetUint64Slot(fbb, 0, id)
+	fbutils.SetUint64Slot(fbb, 1, rIdCustomerId)
+	fbutils.SetInt64Slot(fbb, 2, obj.Date)
+	fbutils.SetFloat64Slot(fbb, 3, obj.Amount)
+	return nil
+}
+
+// Load is called by ObjectBox to load an object from a FlatBuffer
+func (order_EntityInfo) Load(ob *objectbox.ObjectBox, bytes []byte) (interface{}, error) {
+	if len(bytes) == 0 { // sanity check, should "never" happen
+		return nil, errors.New("can't deserialize an object of type 'Order' - no data received")
+	}
+
+	var table = &flatbuffers.Table{
+		Bytes: bytes,
+		Pos:   flatbuffers.GetUOffsetT(bytes),
+	}
+
+	var propId = table.GetUint64Slot(4, 0)
+
+	return &models.Order{
+		Id:         propId,
+		CustomerId: fbutils.GetUint64Slot(table, 6),
+		Date:       fbutils.GetInt64Slot(table, 8),
+		Amount:     fbutils.GetFloat64Slot(table, 10),
+	}, nil
+}
+
+// MakeSlice is called by ObjectBox to construct a new slice to hold the read objects
+func (order_EntityInfo) MakeSlice(capacity int) interface{} {
+	return make([]*models.Order, 0, capacity)
+}
+
+// AppendToSlice is called by ObjectBox to fill the slice of the read objects
+func (order_EntityInfo) AppendToSlice(slice interface{}, object interface{}) interface{} {
+	if object == nil {
+		return append(slice.([]*models.Order), nil)
+	}
+	return append(slice.([]*models.Order), object.(*models.Order))
+}
+
+// Box provides CRUD access to Order objects
+type OrderBox struct {
+	*objectbox.Box
+}
+
+// BoxForOrder opens a box of Order objects
+func BoxForOrder(ob *objectbox.ObjectBox) *OrderBox {
+	return &OrderBox{
+		Box: ob.InternalBox(4),
+	}
+}
+
+// Put synchronously inserts/updates a single object.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the Order.Id property on the passed object will be assigned the new ID as well.
+func (box *OrderBox) Put(object *models.Order) (uint64, error) {
+	return box.Box.Put(object)
+}
+
+// Insert synchronously inserts a single object. As opposed to Put, Insert will fail if given an ID that already exists.
+// In case the Id is not specified, it would be assigned automatically (auto-increment).
+// When inserting, the Order.Id property on the passed object will be assigned the new ID as well.
+func (box *OrderBox) Insert(object *models.Order) (uint64, error) {
+	return box.Box.Insert(object)
+}
+
+// Update synchronously updates a single object.
+// As opposed to Put, Update will fail if an object with the same ID is not found in the database.
+func (box *OrderBox) Update(object *models.Order) error {
+	return box.Box.Update(object)
+}
+
+// PutAsync asynchronously inserts/updates a single object.
+// Deprecated: use box.Async().Put() instead
+func (box *OrderBox) PutAsync(object *models.Order) (uint64, error) {
+	return box.Box.PutAsync(object)
+}
+
+// PutMany inserts multiple objects in single transaction.
+// In case Ids are not set on the objects, they would be assigned automatically (auto-increment).
+//
+// Returns: IDs of the put objects (in the same order).
+// When inserting, the Order.Id property on the objects in the slice will be assigned the new IDs as well.
+//
+// Note: In case an error occurs during the transaction, some of the objects may already have the Order.Id assigned
+// even though the transaction has been rolled back and the objects are not stored under those IDs.
+//
+// Note: The slice may be empty or even nil; in both cases, an empty IDs slice and no error is returned.
+func (box *OrderBox) PutMany(objects []*models.Order) ([]uint64, error) {
+	return box.Box.PutMany(objects)
+}
+
+// Get reads a single object.
+//
+// Returns nil (and no error) in case the object with the given ID doesn't exist.
+func (box *OrderBox) Get(id uint64) (*models.Order, error) {
+	object, err := box.Box.Get(id)
+	if err != nil {
+		return nil, err
+	} else if object == nil {
+		return nil, nil
+	}
+	return object.(*models.Order), nil
+}
+
+// GetMany reads multiple objects at once.
+// If any of the objects doesn't exist, its position in the return slice is nil
+func (box *OrderBox) GetMany(ids ...uint64) ([]*models.Order, error) {
+	objects, err := box.Box.GetMany(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Order), nil
+}
+
+// GetManyExisting reads multiple objects at once, skipping those that do not exist.
+func (box *OrderBox) GetManyExisting(ids ...uint64) ([]*models.Order, error) {
+	objects, err := box.Box.GetManyExisting(ids...)
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Order), nil
+}
+
+// GetAll reads all stored objects
+func (box *OrderBox) GetAll() ([]*models.Order, error) {
+	objects, err := box.Box.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Order), nil
+}
+
+// Remove deletes a single object
+func (box *OrderBox) Remove(object *models.Order) error {
+	return box.Box.Remove(object)
+}
+
+// RemoveMany deletes multiple objects at once.
+// Returns the number of deleted object or error on failure.
+// Note that this method will not fail if an object is not found (e.g. already removed).
+// In case you need to strictly check whether all of the objects exist before removing them,
+// you can execute multiple box.Contains() and box.Remove() inside a single write transaction.
+func (box *OrderBox) RemoveMany(objects ...*models.Order) (uint64, error) {
+	var ids = make([]uint64, len(objects))
+	for k, object := range objects {
+		ids[k] = object.Id
+	}
+	return box.Box.RemoveIds(ids...)
+}
+
+// Creates a query with the given conditions. Use the fields of the Order_ struct to create conditions.
+// Keep the *OrderQuery if you intend to execute the query multiple times.
+// Note: this function panics if you try to create illegal queries; e.g. use properties of an alien type.
+// This is typically a programming error. Use QueryOrError instead if you want the explicit error check.
+func (box *OrderBox) Query(conditions ...objectbox.Condition) *OrderQuery {
+	return &OrderQuery{
+		box.Box.Query(conditions...),
+	}
+}
+
+// Creates a query with the given conditions. Use the fields of the Order_ struct to create conditions.
+// Keep the *OrderQuery if you intend to execute the query multiple times.
+func (box *OrderBox) QueryOrError(conditions ...objectbox.Condition) (*OrderQuery, error) {
+	if query, err := box.Box.QueryOrError(conditions...); err != nil {
+		return nil, err
+	} else {
+		return &OrderQuery{query}, nil
+	}
+}
+
+// Async provides access to the default Async Box for asynchronous operations. See OrderAsyncBox for more information.
+func (box *OrderBox) Async() *OrderAsyncBox {
+	return &OrderAsyncBox{AsyncBox: box.Box.Async()}
+}
+
+// OrderAsyncBox provides asynchronous operations on Order objects.
+//
+// Asynchronous operations are executed on a separate internal thread for better performance.
+//
+// There are two main use cases:
+//
+// 1) "execute & forget:" you gain faster put/remove operations as you don't have to wait for the transaction to finish.
+//
+// 2) Many small transactions: if your write load is typically a lot of individual puts that happen in parallel,
+// this will merge small transactions into bigger ones. This results in a significant gain in overall throughput.
+//
+// In situations with (extremely) high async load, an async method may be throttled (~1ms) or delayed up to 1 second.
+// In the unlikely event that the object could still not be enqueued (full queue), an error will be returned.
+//
+// Note that async methods do not give you hard durability guarantees like the synchronous Box provides.
+// There is a small time window in which the data may not have been committed durably yet.
+type OrderAsyncBox struct {
+	*objectbox.AsyncBox
+}
+
+// AsyncBoxForOrder creates a new async box with the given operation timeout in case an async queue is full.
+// The returned struct must be freed explicitly using the Close() method.
+// It's usually preferable to use OrderBox::Async() which takes care of resource management and doesn't require closing.
+func AsyncBoxForOrder(ob *objectbox.ObjectBox, timeoutMs uint64) *OrderAsyncBox {
+	var async, err = objectbox.NewAsyncBox(ob, 4, timeoutMs)
+	if err != nil {
+		panic("Could not create async box for entity ID 4: %s" + err.Error())
+	}
+	return &OrderAsyncBox{AsyncBox: async}
+}
+
+// Put inserts/updates a single object asynchronously.
+// When inserting a new object, the Id property on the passed object will be assigned the new ID the entity would hold
+// if the insert is ultimately successful. The newly assigned ID may not become valid if the insert fails.
+func (asyncBox *OrderAsyncBox) Put(object *models.Order) (uint64, error) {
+	return asyncBox.AsyncBox.Put(object)
+}
+
+// Insert a single object asynchronously.
+// The Id property on the passed object will be assigned the new ID the entity would hold if the insert is ultimately
+// successful. The newly assigned ID may not become valid if the insert fails.
+// Fails silently if an object with the same ID already exists (this error is not returned).
+func (asyncBox *OrderAsyncBox) Insert(object *models.Order) (id uint64, err error) {
+	return asyncBox.AsyncBox.Insert(object)
+}
+
+// Update a single object asynchronously.
+// The object must already exists or the update fails silently (without an error returned).
+func (asyncBox *OrderAsyncBox) Update(object *models.Order) error {
+	return asyncBox.AsyncBox.Update(object)
+}
+
+// Remove deletes a single object asynchronously.
+func (asyncBox *OrderAsyncBox) Remove(object *models.Order) error {
+	return asyncBox.AsyncBox.Remove(object)
+}
+
+// Query provides a way to search stored objects
+//
+// For example, you can find all Order which Id is either 42 or 47:
+//
+// box.Query(Order_.Id.In(42, 47)).Find()
+type OrderQuery struct {
+	*objectbox.Query
+}
+
+// Find returns all objects matching the query
+func (query *OrderQuery) Find() ([]*models.Order, error) {
+	objects, err := query.Query.Find()
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]*models.Order), nil
+}
+
+// Offset defines the index of the first object to process (how many objects to skip)
+func (query *OrderQuery) Offset(offset uint64) *OrderQuery {
+	query.Query.Offset(offset)
+	return query
+}
+
+// Limit sets the number of elements to process by the query
+func (query *OrderQuery) Limit(limit uint64) *OrderQuery {
+	query.Query.Limit(limit)
+	return query
+}